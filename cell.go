@@ -66,7 +66,7 @@ func (cell *Cell) IsOccupied() bool {
 type CellSelection struct {
 	StartX, StartY, EndX, EndY int // The start and end position of the Cell in cellular locations.
 	space                      *Space
-	excludeSelf                IShape
+	selector                   IShape // The Shape that made the selection, if any; it's excluded from the selection, as are Shapes it can't collide with.
 }
 
 // FilterShapes returns a ShapeFilter of the shapes within the cell selection.
@@ -82,7 +82,7 @@ func (c CellSelection) FilterShapes() ShapeFilter {
 
 }
 
-// ForEach loops through each shape in the CellSelection.
+// ForEach loops through each active shape in the CellSelection.
 func (c CellSelection) ForEach(iterationFunction func(shape IShape) bool) {
 	// Internally, this function allows us to pass a CellSelection as the operatingOn property in a ShapeFilter.
 
@@ -98,7 +98,7 @@ func (c CellSelection) ForEach(iterationFunction func(shape IShape) bool) {
 
 				for _, s := range cell.Shapes {
 
-					if s == c.excludeSelf {
+					if !s.IsActive() || (c.selector != nil && !c.selector.CanCollideWith(s)) {
 						continue
 					}
 
@@ -119,3 +119,47 @@ func (c CellSelection) ForEach(iterationFunction func(shape IShape) bool) {
 	}
 
 }
+
+// CellLineSelection is a selection of the Cells that a line passes through, in order from the start of the line to the end; see Space.FilterCellsInLine().
+type CellLineSelection struct {
+	Cells []*Cell // The Cells the line passes through.
+	space *Space
+}
+
+// FilterShapes returns a ShapeFilter of the shapes within the cell selection.
+func (c CellLineSelection) FilterShapes() ShapeFilter {
+
+	if c.space == nil {
+		return ShapeFilter{}
+	}
+
+	return ShapeFilter{
+		operatingOn: c,
+	}
+
+}
+
+// ForEach loops through each active shape in the CellLineSelection, in the order of the Cells the line passes through.
+func (c CellLineSelection) ForEach(iterationFunction func(shape IShape) bool) {
+
+	cellLineSelectionForEachIDSet = cellLineSelectionForEachIDSet[:0]
+
+	for _, cell := range c.Cells {
+
+		for _, s := range cell.Shapes {
+
+			if !s.IsActive() || cellLineSelectionForEachIDSet.idInSet(s.ID()) {
+				continue
+			}
+
+			cellLineSelectionForEachIDSet = append(cellLineSelectionForEachIDSet, s.ID())
+
+			if !iterationFunction(s) {
+				return
+			}
+
+		}
+
+	}
+
+}
@@ -0,0 +1,380 @@
+package resolv
+
+import "math"
+
+// CharacterController is a kinematic character controller - it moves a Shape through its Space by a desired velocity, sliding along any
+// surfaces it hits along the way, and reports what the Shape is touching (floors, walls, and ceilings) afterwards.
+// This takes care of the usual dance of casting downwards, pushing out of walls, and zeroing out speed that character movement generally needs.
+type CharacterController struct {
+	Shape IShape // The Shape to move. This can be any kind of Shape (i.e. a ConvexPolygon or a Circle), and should be added to a Space.
+	// Up is the up direction for the character, used to determine what counts as floors and ceilings.
+	// It defaults to {0, -1}, which is up in screen-space (where +Y points down).
+	Up        Vector
+	MaxSlides int     // The maximum number of times the Shape can slide along surfaces in a single Move() call. Defaults to 4.
+	Margin    float64 // The distance to keep between the Shape and any surfaces it slides along. Defaults to 0.01.
+	// Filter is an optional function to select which Shapes the character collides with; if it returns true for a Shape, that Shape is solid.
+	// If Filter is nil, all Shapes the character's Shape can collide with (see ShapeBase.CanCollideWith()) are solid.
+	Filter func(shape IShape) bool
+	// MaxSlopeAngle is the maximum angle (in radians) between a surface's normal and the Up vector for the surface to be walkable (a floor).
+	// Steeper surfaces are walls, and the character can't climb them by walking. Defaults to 45 degrees.
+	MaxSlopeAngle float64
+	// SnapDistance is how far the character can be snapped down to the ground after moving if it was on the floor before moving and isn't moving upwards.
+	// This keeps the character glued to descending slopes and stops it from popping off of small bumps. Defaults to 0 (off).
+	SnapDistance float64
+	// StepHeight is the maximum height of obstacles (like stairs or small ledges between tiles) that the character automatically steps up onto
+	// when walking into them while on the floor. Defaults to 0 (off).
+	StepHeight float64
+	// RidePlatforms indicates whether the character should ride the floor it's standing on - when true, the floor Shape is set as the
+	// character's Shape's carrier (see ShapeBase.SetCarrier()), so the character moves along with it (and is swung around it as it rotates,
+	// though the character's Shape itself isn't rotated). Defaults to true.
+	RidePlatforms bool
+	// InheritPlatformVelocity indicates whether the character should inherit the velocity of the platform it's riding when it leaves it (for example, by jumping off);
+	// the platform's velocity is added to the velocity returned from Move(). Platform velocity is tracked by Space.Step(). Defaults to false.
+	InheritPlatformVelocity bool
+
+	onFloor, onWall, onCeiling             bool
+	wasOnFloor                             bool
+	floorNormal, wallNormal, ceilingNormal Vector
+	floorShape                             IShape
+	platform                               IShape
+	touched                                ShapeCollection
+}
+
+// NewCharacterController creates a new CharacterController to move the given Shape.
+func NewCharacterController(shape IShape) *CharacterController {
+	return &CharacterController{
+		Shape:         shape,
+		Up:            NewVector(0, -1),
+		MaxSlides:     4,
+		Margin:        0.01,
+		MaxSlopeAngle: ToRadians(45),
+		RidePlatforms: true,
+	}
+}
+
+// Move moves the CharacterController's Shape by the given velocity, sliding along any surfaces it hits.
+// When walking on walkable slopes, the character keeps its speed along the slope, rather than slowing down going up them.
+// Move returns the velocity with any components going into the surfaces hit removed; the returned velocity can be stored
+// and passed back into Move() next frame (so, for example, falling speed is zeroed out upon landing on a floor).
+func (cc *CharacterController) Move(velocity Vector) Vector {
+
+	cc.wasOnFloor = cc.onFloor
+	cc.onFloor = false
+	cc.onWall = false
+	cc.onCeiling = false
+	cc.floorNormal = Vector{}
+	cc.wallNormal = Vector{}
+	cc.ceilingNormal = Vector{}
+	cc.floorShape = nil
+	cc.touched = cc.touched[:0]
+
+	up := cc.Up.Unit()
+	movingUp := velocity.Dot(up) > 0
+
+	candidates := cc.candidates(velocity.Magnitude() + cc.SnapDistance + cc.StepHeight)
+
+	remaining := velocity
+
+	for i := 0; i < cc.MaxSlides; i++ {
+
+		if remaining.IsZero() {
+			break
+		}
+
+		hit, ok := sweepShape(cc.Shape, remaining, candidates)
+
+		if !ok {
+			cc.Shape.MoveVec(remaining)
+			break
+		}
+
+		travel := remaining.Scale(hit.Fraction)
+		remaining = remaining.Sub(travel)
+		normal := hit.Normal
+
+		if cc.StepHeight > 0 && !movingUp && (cc.wasOnFloor || cc.onFloor) && !cc.isFloor(normal) && !cc.isCeiling(normal) {
+
+			cc.Shape.MoveVec(travel)
+			travel = Vector{}
+
+			if cc.stepUp(remaining, candidates) {
+				remaining = Vector{}
+				if d := velocity.Dot(up); d < 0 {
+					velocity = velocity.Sub(up.Scale(d))
+				}
+				continue
+			}
+
+		}
+
+		if cc.isFloor(normal) {
+
+			// Walking along a walkable slope, so redirect the lateral movement along the slope to keep the same speed
+			lateral := remaining.Sub(up.Scale(remaining.Dot(up)))
+
+			if !movingUp && !lateral.IsZero() {
+				tangent := normal.Perp()
+				if tangent.Dot(lateral) < 0 {
+					tangent = tangent.Invert()
+				}
+				remaining = tangent.Scale(lateral.Magnitude())
+			} else {
+				remaining = slide(remaining, normal)
+			}
+
+			// Stop falling (rather than sliding down the slope)
+			if d := velocity.Dot(up); d < 0 {
+				velocity = velocity.Sub(up.Scale(d))
+			}
+
+		} else {
+
+			// Steep slopes can't be climbed by walking up them, so they're treated like straight walls instead
+			if !movingUp && normal.Dot(up) > 0 && slide(remaining, normal).Dot(up) > 0 {
+				normal = normal.Sub(up.Scale(normal.Dot(up))).Unit()
+			}
+
+			remaining = slide(remaining, normal)
+			velocity = slide(velocity, normal)
+
+		}
+
+		cc.Shape.MoveVec(travel.Add(normal.Scale(cc.Margin)))
+		cc.addContact(hit.Shape, hit.Normal)
+
+	}
+
+	if cc.wasOnFloor && !cc.onFloor && !movingUp && cc.SnapDistance > 0 {
+		cc.snapToFloor(candidates)
+	}
+
+	cc.depenetrate(candidates)
+
+	return cc.ridePlatform(velocity)
+
+}
+
+// ridePlatform sets the character's Shape's carrier to the floor it's standing on (if RidePlatforms is true), and detaches it when leaving,
+// returning the given velocity with the platform's velocity added if InheritPlatformVelocity is true.
+func (cc *CharacterController) ridePlatform(velocity Vector) Vector {
+
+	// Only change the carrier if the controller set it (so a carrier set by the user isn't overridden)
+	if cc.platform != nil && cc.Shape.Carrier() != cc.platform {
+		cc.platform = nil
+	}
+
+	if cc.RidePlatforms && cc.onFloor {
+
+		if cc.floorShape != cc.platform && (cc.platform != nil || cc.Shape.Carrier() == nil) && cc.Shape.SetCarrier(cc.floorShape) {
+			cc.platform = cc.floorShape
+		}
+
+	} else if cc.platform != nil {
+
+		if cc.InheritPlatformVelocity {
+			velocity = velocity.Add(cc.platform.VelocityAt(cc.Shape.Position()))
+		}
+
+		cc.Shape.SetCarrier(nil)
+		cc.platform = nil
+
+	}
+
+	return velocity
+
+}
+
+// candidates returns the Shapes near the CharacterController's Shape that it could collide with when moving the given distance.
+func (cc *CharacterController) candidates(distance float64) ShapeCollection {
+
+	space := cc.Shape.Space()
+
+	if space == nil {
+		return nil
+	}
+
+	margin := 1 + int(math.Ceil(distance/min(float64(space.cellWidth), float64(space.cellHeight))))
+
+	filter := cc.Shape.SelectTouchingCells(margin).FilterShapes()
+
+	if cc.Filter != nil {
+		filter = filter.ByFunc(cc.Filter)
+	}
+
+	return filter.ByFunc(func(s IShape) bool { return !s.IsSensor() }).Shapes()
+
+}
+
+// snapToFloor moves the CharacterController's Shape down to the floor beneath it, if there's one within SnapDistance.
+func (cc *CharacterController) snapToFloor(candidates ShapeCollection) {
+
+	motion := cc.Up.Unit().Scale(-cc.SnapDistance)
+
+	if hit, ok := sweepShape(cc.Shape, motion, candidates); ok && cc.isFloor(hit.Normal) {
+		cc.Shape.MoveVec(motion.Scale(hit.Fraction).Add(hit.Normal.Scale(cc.Margin)))
+		cc.addContact(hit.Shape, hit.Normal)
+	}
+
+}
+
+// stepUp attempts to step the CharacterController's Shape up and over an obstacle no taller than StepHeight by lifting the Shape up
+// (if there's headroom), moving it along the lateral part of the given motion, and then putting it back down onto a floor.
+// If the step succeeds, stepUp returns true; otherwise, the Shape is left where it was.
+func (cc *CharacterController) stepUp(motion Vector, candidates ShapeCollection) bool {
+
+	up := cc.Up.Unit()
+
+	lateral := motion.Sub(up.Scale(motion.Dot(up)))
+
+	if lateral.IsZero() {
+		return false
+	}
+
+	start := cc.Shape.Position()
+
+	// Check for headroom
+	lift := up.Scale(cc.StepHeight)
+	if hit, ok := sweepShape(cc.Shape, lift, candidates); ok {
+		lift = lift.Scale(hit.Fraction)
+	}
+	cc.Shape.MoveVec(lift)
+
+	// Move forward
+	forward := lateral
+	if hit, ok := sweepShape(cc.Shape, forward, candidates); ok {
+		forward = forward.Scale(hit.Fraction)
+	}
+
+	if forward.Magnitude() <= cc.Margin {
+		cc.Shape.SetPositionVec(start)
+		return false
+	}
+
+	cc.Shape.MoveVec(forward)
+
+	// Put the Shape back down onto the floor
+	drop := lift.Invert().Sub(up.Scale(cc.Margin))
+	hit, ok := sweepShape(cc.Shape, drop, candidates)
+
+	if !ok || !cc.isFloor(hit.Normal) {
+		cc.Shape.SetPositionVec(start)
+		return false
+	}
+
+	cc.Shape.MoveVec(drop.Scale(hit.Fraction).Add(hit.Normal.Scale(cc.Margin)))
+	cc.addContact(hit.Shape, hit.Normal)
+
+	return true
+
+}
+
+// isFloor returns whether a surface with the given normal is walkable.
+func (cc *CharacterController) isFloor(normal Vector) bool {
+	return normal.Dot(cc.Up.Unit()) >= math.Cos(cc.MaxSlopeAngle)
+}
+
+// isCeiling returns whether a surface with the given normal is a ceiling.
+func (cc *CharacterController) isCeiling(normal Vector) bool {
+	return normal.Dot(cc.Up.Unit()) <= -math.Cos(cc.MaxSlopeAngle)
+}
+
+// depenetrate pushes the CharacterController's Shape out of any Shapes it's still overlapping.
+func (cc *CharacterController) depenetrate(candidates ShapeCollection) {
+	_, contacts := ResolveOverlaps(cc.Shape, candidates, 4)
+	for _, set := range contacts {
+		cc.addContact(set.OtherShape, set.MTV.Unit())
+	}
+}
+
+func (cc *CharacterController) addContact(shape IShape, normal Vector) {
+
+	if cc.isFloor(normal) {
+		cc.onFloor = true
+		cc.floorNormal = normal
+		cc.floorShape = shape
+	} else if cc.isCeiling(normal) {
+		cc.onCeiling = true
+		cc.ceilingNormal = normal
+	} else {
+		cc.onWall = true
+		cc.wallNormal = normal
+	}
+
+	for _, t := range cc.touched {
+		if t == shape {
+			return
+		}
+	}
+
+	cc.touched = append(cc.touched, shape)
+
+}
+
+// SlopeAngle returns the angle (in radians) of the floor the character is standing on after the last Move() call, relative to the Up vector.
+// If the character isn't on a floor, this returns 0.
+func (cc *CharacterController) SlopeAngle() float64 {
+	if !cc.onFloor {
+		return 0
+	}
+	return math.Acos(clamp(cc.floorNormal.Dot(cc.Up.Unit()), -1, 1))
+}
+
+// IsOnFloor returns whether the CharacterController's Shape touched a floor during the last Move() call.
+func (cc *CharacterController) IsOnFloor() bool {
+	return cc.onFloor
+}
+
+// IsOnWall returns whether the CharacterController's Shape touched a wall during the last Move() call.
+func (cc *CharacterController) IsOnWall() bool {
+	return cc.onWall
+}
+
+// IsOnCeiling returns whether the CharacterController's Shape touched a ceiling during the last Move() call.
+func (cc *CharacterController) IsOnCeiling() bool {
+	return cc.onCeiling
+}
+
+// FloorNormal returns the normal of the floor touched during the last Move() call; if no floor was touched, this returns a zero Vector.
+func (cc *CharacterController) FloorNormal() Vector {
+	return cc.floorNormal
+}
+
+// WallNormal returns the normal of the wall touched during the last Move() call; if no wall was touched, this returns a zero Vector.
+func (cc *CharacterController) WallNormal() Vector {
+	return cc.wallNormal
+}
+
+// CeilingNormal returns the normal of the ceiling touched during the last Move() call; if no ceiling was touched, this returns a zero Vector.
+func (cc *CharacterController) CeilingNormal() Vector {
+	return cc.ceilingNormal
+}
+
+// Platform returns the Shape the character is riding (see RidePlatforms), or nil if it isn't riding one.
+func (cc *CharacterController) Platform() IShape {
+	return cc.platform
+}
+
+// FloorShape returns the Shape the CharacterController's Shape is standing on after the last Move() call, or nil if it's not on a floor.
+func (cc *CharacterController) FloorShape() IShape {
+	return cc.floorShape
+}
+
+// Touched returns a new ShapeCollection consisting of all of the Shapes touched during the last Move() call.
+func (cc *CharacterController) Touched() ShapeCollection {
+	return append(make(ShapeCollection, 0, len(cc.touched)), cc.touched...)
+}
+
+// slide returns the given vector with any component going into the surface with the given normal removed.
+func slide(vec, normal Vector) Vector {
+	if d := vec.Dot(normal); d < 0 {
+		vec = vec.Sub(normal.Scale(d))
+	}
+	return vec
+}
+
+/////
+
+// isPenetrating returns whether the given IntersectionSet indicates that the Shapes involved are actually overlapping, rather than just touching.
+func isPenetrating(set IntersectionSet) bool {
+	return !set.IsEmpty() && !set.MTV.IsZero()
+}
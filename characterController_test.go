@@ -0,0 +1,231 @@
+package resolv
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCharacterControllerMove(t *testing.T) {
+
+	tests := []struct {
+		name                  string
+		start, velocity       Vector
+		wantPos, wantVelocity Vector
+		floor, wall, ceiling  bool
+	}{
+		{"moving freely", Vector{100, 100}, Vector{10, 10}, Vector{110, 110}, Vector{10, 10}, false, false, false},
+		{"falling onto the floor", Vector{100, 150}, Vector{0, 60}, Vector{100, 190}, Vector{}, true, false, false},
+		{"falling onto the floor while moving", Vector{100, 150}, Vector{20, 60}, Vector{120, 190}, Vector{20, 0}, true, false, false},
+		{"walking into a wall", Vector{280, 190}, Vector{30, 0}, Vector{290, 190}, Vector{}, false, true, false},
+		{"sliding down a wall", Vector{280, 100}, Vector{30, 20}, Vector{290, 120}, Vector{0, 20}, false, true, false},
+		{"jumping into the ceiling", Vector{100, 40}, Vector{0, -30}, Vector{100, 30}, Vector{}, false, false, true},
+		{"falling into the corner", Vector{280, 150}, Vector{30, 60}, Vector{290, 190}, Vector{}, true, true, false},
+	}
+
+	for _, test := range tests {
+
+		t.Run(test.name, func(t *testing.T) {
+
+			space := NewSpace(640, 480, 16, 16)
+			space.Add(
+				NewRectangleFromTopLeft(0, 200, 640, 20), // Floor
+				NewRectangleFromTopLeft(300, 0, 20, 200), // Wall
+				NewRectangleFromTopLeft(0, 0, 300, 20),   // Ceiling
+			)
+
+			player := NewRectangle(test.start.X, test.start.Y, 20, 20)
+			space.Add(player)
+
+			cc := NewCharacterController(player)
+			velocity := cc.Move(test.velocity)
+
+			// The character keeps a small margin away from any surfaces it hits
+			if player.Position().Sub(test.wantPos).Magnitude() > cc.Margin*2 {
+				t.Errorf("moved to %v, want %v", player.Position(), test.wantPos)
+			}
+
+			if velocity.Sub(test.wantVelocity).Magnitude() > 1e-6 {
+				t.Errorf("returned a velocity of %v, want %v", velocity, test.wantVelocity)
+			}
+
+			if cc.IsOnFloor() != test.floor || cc.IsOnWall() != test.wall || cc.IsOnCeiling() != test.ceiling {
+				t.Errorf("on floor, wall, and ceiling = %v, %v, %v; want %v, %v, %v", cc.IsOnFloor(), cc.IsOnWall(), cc.IsOnCeiling(), test.floor, test.wall, test.ceiling)
+			}
+
+		})
+
+	}
+
+}
+
+func TestCharacterControllerDepenetration(t *testing.T) {
+
+	for _, reversed := range []bool{false, true} {
+
+		space := NewSpace(640, 480, 16, 16)
+
+		left := NewRectangleFromTopLeft(0, 100, 50, 20)
+		right := NewRectangleFromTopLeft(50, 100, 50, 20)
+
+		if reversed {
+			space.Add(right, left)
+		} else {
+			space.Add(left, right)
+		}
+
+		// Sunk into the seam between the two tiles
+		player := NewRectangleFromTopLeft(40, 95, 20, 20)
+		space.Add(player)
+
+		cc := NewCharacterController(player)
+		cc.Move(Vector{})
+
+		if want := (Vector{50, 90}); player.Position().Sub(want).Magnitude() > 1e-6 {
+			t.Errorf("player is at %v, want %v", player.Position(), want)
+		}
+
+		if !cc.IsOnFloor() || cc.IsOnWall() {
+			t.Errorf("IsOnFloor() = %v and IsOnWall() = %v, want true and false", cc.IsOnFloor(), cc.IsOnWall())
+		}
+
+	}
+
+}
+
+func TestCharacterControllerSlopes(t *testing.T) {
+
+	tests := []struct {
+		name         string
+		slope        float64 // The angle of the ramp, in degrees
+		wantClimb    bool
+		wantAngle    float64
+		wantDistance float64
+	}{
+		{"walking up a gentle slope", 30, true, 30, 40},
+		{"walking up the steepest walkable slope", 44, true, 44, 40},
+		{"walking into a steep slope", 60, false, 0, 0},
+	}
+
+	for _, test := range tests {
+
+		t.Run(test.name, func(t *testing.T) {
+
+			space := NewSpace(640, 480, 16, 16)
+
+			rise := 200 * math.Tan(ToRadians(test.slope))
+			ramp := NewConvexPolygonVec(Vector{200, 200}, []Vector{{0, 0}, {200, -rise}, {200, 0}})
+			space.Add(NewRectangleFromTopLeft(0, 200, 640, 20), ramp)
+
+			// Standing on the floor, right at the foot of the ramp
+			player := NewRectangle(190, 190-0.01, 20, 20)
+			space.Add(player)
+			start := player.Position()
+
+			cc := NewCharacterController(player)
+			cc.Move(Vector{40, 0})
+
+			moved := player.Position().Sub(start)
+
+			if climbed := moved.Y < -1; climbed != test.wantClimb {
+				t.Fatalf("climbed the ramp = %v (moved %v), want %v", climbed, moved, test.wantClimb)
+			}
+
+			if !test.wantClimb {
+				if !cc.IsOnWall() {
+					t.Errorf("a steep slope should be a wall")
+				}
+				return
+			}
+
+			// Walking up a slope shouldn't slow the character down
+			if math.Abs(moved.Magnitude()-test.wantDistance) > 0.1 {
+				t.Errorf("moved %v, want %v", moved.Magnitude(), test.wantDistance)
+			}
+
+			if !cc.IsOnFloor() || math.Abs(ToDegrees(cc.SlopeAngle())-test.wantAngle) > 0.1 {
+				t.Errorf("IsOnFloor() = %v, SlopeAngle() = %v degrees; want true, %v degrees", cc.IsOnFloor(), ToDegrees(cc.SlopeAngle()), test.wantAngle)
+			}
+
+		})
+
+	}
+
+}
+
+func TestCharacterControllerSnapping(t *testing.T) {
+
+	for _, snap := range []float64{0, 20} {
+
+		space := NewSpace(640, 480, 16, 16)
+
+		// A plateau, with a 30 degree slope going down from its right edge
+		drop := 100.0
+		space.Add(
+			NewRectangleFromTopLeft(0, 100, 200, 100),
+			NewConvexPolygonVec(Vector{200, 100}, []Vector{{0, 0}, {drop / math.Tan(ToRadians(30)), drop}, {0, drop}}),
+		)
+
+		player := NewRectangle(180, 90, 20, 20)
+		space.Add(player)
+
+		cc := NewCharacterController(player)
+		cc.SnapDistance = snap
+
+		// Land on the plateau, and then walk off of the edge and down the slope
+		cc.Move(Vector{0, 1})
+		for i := 0; i < 4; i++ {
+			cc.Move(Vector{10, 0})
+		}
+
+		if snapped := snap > 0; cc.IsOnFloor() != snapped {
+			t.Errorf("with a SnapDistance of %v, IsOnFloor() = %v, want %v", snap, cc.IsOnFloor(), snapped)
+		}
+
+		if snap > 0 && (player.Position().Y <= 90 || math.Abs(ToDegrees(cc.SlopeAngle())-30) > 0.1) {
+			t.Errorf("wasn't snapped down onto the slope; at %v on a slope of %v degrees", player.Position(), ToDegrees(cc.SlopeAngle()))
+		}
+
+	}
+
+}
+
+func TestCharacterControllerStepUp(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		height  float64
+		wantPos Vector
+	}{
+		{"stepping up onto a low step", 5, Vector{205, 185}},
+		{"stepping up onto the highest step", 8, Vector{205, 182}},
+		{"walking into a high step", 12, Vector{190, 190}},
+	}
+
+	for _, test := range tests {
+
+		t.Run(test.name, func(t *testing.T) {
+
+			space := NewSpace(640, 480, 16, 16)
+			space.Add(
+				NewRectangleFromTopLeft(0, 200, 640, 20),
+				NewRectangleFromTopLeft(200, 200-test.height, 100, test.height),
+			)
+
+			player := NewRectangle(185, 190-0.01, 20, 20)
+			space.Add(player)
+
+			cc := NewCharacterController(player)
+			cc.StepHeight = 8
+
+			cc.Move(Vector{0, 1})
+			cc.Move(Vector{20, 0})
+
+			if player.Position().Sub(test.wantPos).Magnitude() > cc.Margin*2 {
+				t.Errorf("moved to %v, want %v", player.Position(), test.wantPos)
+			}
+
+		})
+
+	}
+
+}
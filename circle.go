@@ -26,6 +26,10 @@ func (c *Circle) Clone() IShape {
 	newCircle.ShapeBase.space = nil
 	newCircle.ShapeBase.touchingCells = []*Cell{}
 	newCircle.ShapeBase.owner = newCircle
+	newCircle.ShapeBase.ignoredShapes = append(ShapeCollection{}, c.ignoredShapes...)
+	newCircle.ShapeBase.passingThrough = nil
+	newCircle.ShapeBase.carrier = nil
+	newCircle.ShapeBase.riders = nil
 	return newCircle
 }
 
@@ -38,6 +42,7 @@ func (c *Circle) Bounds() Bounds {
 	}
 }
 
+// Project projects (i.e. flattens) the Circle onto the provided axis.
 func (c *Circle) Project(axis Vector) Projection {
 	axis = axis.Unit()
 	projectedCenter := axis.Dot(c.position)
@@ -66,14 +71,15 @@ func (c *Circle) SetRadius(radius float64) {
 
 // Intersection returns an IntersectionSet for the other Shape provided.
 // If no intersection is detected, the IntersectionSet returned is empty.
+// If either Shape is a sensor, the IntersectionSet is flagged as such and its MTV is zero.
 func (c *Circle) Intersection(other IShape) IntersectionSet {
 
 	switch otherShape := other.(type) {
 	case *ConvexPolygon:
-		return circleConvexTest(c, otherShape)
+		return applySensor(circleConvexTest(c, otherShape), c, other)
 
 	case *Circle:
-		return circleCircleTest(c, otherShape)
+		return applySensor(circleCircleTest(c, otherShape), c, other)
 	}
 
 	// This should never happen
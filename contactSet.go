@@ -17,6 +17,7 @@ type IntersectionSet struct {
 	Center        Vector         // Center of the Contact set; this is the average of all Points contained within all contacts in the IntersectionSet.
 	MTV           Vector         // Minimum Translation Vector; this is the vector to move a Shape on to move it to contact with the other, intersecting / contacting Shape.
 	OtherShape    IShape         // The other shape involved in the contact.
+	IsSensor      bool           // Whether either Shape involved in the contact is a sensor; if so, the MTV is zero.
 }
 
 func newIntersectionSet() IntersectionSet {
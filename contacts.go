@@ -0,0 +1,147 @@
+package resolv
+
+// PairID is a stable identifier for a pair of Shapes; the same pair of Shapes always has the same PairID, regardless of their order.
+type PairID uint64
+
+// NewPairID returns the PairID for the given pair of Shapes.
+func NewPairID(a, b IShape) PairID {
+	idA, idB := a.ID(), b.ID()
+	if idA > idB {
+		idA, idB = idB, idA
+	}
+	return PairID(uint64(idA)<<32 | uint64(idB))
+}
+
+// Contact represents a contact (an overlap) between two Shapes in a Space, as tracked by Space.Step().
+type Contact struct {
+	ID     PairID          // The stable ID of the pair of Shapes in contact.
+	ShapeA IShape          // The first Shape in the contact (the Shape with the lower ID).
+	ShapeB IShape          // The second Shape in the contact (the Shape with the higher ID).
+	Set    IntersectionSet // The IntersectionSet of the contact from ShapeA's perspective (so Set.OtherShape is ShapeB).
+}
+
+// Other returns the other Shape involved in the contact, given one of them. If the given Shape isn't part of the contact, Other returns nil.
+func (c Contact) Other(shape IShape) IShape {
+	if shape == c.ShapeA {
+		return c.ShapeB
+	} else if shape == c.ShapeB {
+		return c.ShapeA
+	}
+	return nil
+}
+
+// ContactListener is a struct of callbacks to be called by Space.Step() when contacts between Shapes in the Space change.
+type ContactListener struct {
+	OnEnter func(contact Contact) // OnEnter is called when two Shapes start overlapping.
+	OnStay  func(contact Contact) // OnStay is called each Step() that two Shapes continue overlapping after entering.
+	OnExit  func(contact Contact) // OnExit is called when two Shapes stop overlapping; the contact passed is the last one found for the pair.
+}
+
+// SetContactListener sets the ContactListener for the Space, which contains callbacks that are called from Space.Step().
+func (s *Space) SetContactListener(listener ContactListener) {
+	s.contactListener = listener
+}
+
+// Contacts returns a new slice consisting of the contacts found in the Space during the last Step() call.
+func (s *Space) Contacts() []Contact {
+	return append(make([]Contact, 0, len(s.contacts)), s.contacts...)
+}
+
+// Step steps the Space's contact tracking forward by a frame; it should be called once per game frame, after Shapes have been moved.
+// Step also updates the velocity of each Shape in the Space (see ShapeBase.Velocity()).
+// Internally, the Space uses its Cells to find the pairs of Shapes that overlap, and then calls the
+// callbacks set using Space.SetContactListener() for pairs that started overlapping, continue to overlap, or stopped overlapping since the last Step().
+// Only pairs of Shapes that can collide (see ShapeBase.CanCollideWith()) are tracked.
+func (s *Space) Step() {
+
+	for _, shape := range s.shapes {
+		shape.base().updateVelocity()
+	}
+
+	s.prevContacts, s.contacts = s.contacts, s.prevContacts[:0]
+
+	s.prevContactIDs, s.contactIDs = s.contactIDs, s.prevContactIDs
+	s.contactIDs.Clear()
+	s.checkedPairs.Clear()
+
+	for y := range s.cells {
+
+		for x := range s.cells[y] {
+
+			cell := s.cells[y][x]
+
+			for i, a := range cell.Shapes {
+
+				for _, b := range cell.Shapes[i+1:] {
+
+					id := NewPairID(a, b)
+
+					if s.checkedPairs.Contains(id) {
+						continue
+					}
+
+					s.checkedPairs.Add(id)
+
+					if !a.CanCollideWith(b) {
+						continue
+					}
+
+					if a.ID() < b.ID() {
+						s.addContact(id, a, b)
+					} else {
+						s.addContact(id, b, a)
+					}
+
+				}
+
+			}
+
+		}
+
+	}
+
+	for _, contact := range s.contacts {
+
+		if s.prevContactIDs.Contains(contact.ID) {
+			if s.contactListener.OnStay != nil {
+				s.contactListener.OnStay(contact)
+			}
+		} else if s.contactListener.OnEnter != nil {
+			s.contactListener.OnEnter(contact)
+		}
+
+	}
+
+	for _, contact := range s.prevContacts {
+
+		if !s.contactIDs.Contains(contact.ID) && s.contactListener.OnExit != nil {
+			s.contactListener.OnExit(contact)
+		}
+
+	}
+
+}
+
+func (s *Space) addContact(id PairID, a, b IShape) {
+
+	set := a.Intersection(b)
+
+	// A Shape entirely inside of the other (e.g. a player inside of a large trigger zone) is still in contact with it
+	if set.IsEmpty() {
+		contained, ok := containmentSet(a, b)
+		if !ok {
+			return
+		}
+		set = contained
+		set.IsSensor = a.IsSensor() || b.IsSensor()
+	}
+
+	s.contactIDs.Add(id)
+	s.contacts = append(s.contacts, Contact{
+		ID:     id,
+		ShapeA: a,
+		ShapeB: b,
+		Set:    set,
+	})
+
+}
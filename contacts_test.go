@@ -0,0 +1,104 @@
+package resolv
+
+import (
+	"testing"
+)
+
+func TestSpaceStepContacts(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		shape     IShape
+		positions []Vector
+		want      []string // The event (if any) for the contact between the Shape and the wall in each Step()
+	}{
+		{"moving across the wall", NewRectangle(50, 50, 20, 20),
+			[]Vector{{50, 50}, {100, 50}, {105, 50}, {150, 50}, {150, 50}},
+			[]string{"", "enter", "stay", "exit", ""}},
+		{"moving inside of the wall", NewCircle(50, 50, 3),
+			[]Vector{{50, 50}, {110, 50}, {110, 60}, {50, 50}},
+			[]string{"", "enter", "stay", "exit"}},
+		{"teleporting through the wall", NewRectangle(50, 50, 20, 20),
+			[]Vector{{50, 50}, {200, 50}},
+			[]string{"", ""}},
+	}
+
+	for _, test := range tests {
+
+		t.Run(test.name, func(t *testing.T) {
+
+			space := NewSpace(640, 480, 16, 16)
+
+			wall := NewRectangleFromTopLeft(100, 0, 20, 100)
+			space.Add(wall, test.shape)
+
+			event := ""
+			record := func(name string) func(Contact) {
+				return func(contact Contact) {
+					if contact.Other(wall) != test.shape {
+						t.Errorf("got a contact between %v and %v", contact.ShapeA, contact.ShapeB)
+					}
+					if event != "" {
+						t.Errorf("got both %s and %s events in the same Step()", event, name)
+					}
+					event = name
+				}
+			}
+
+			space.SetContactListener(ContactListener{
+				OnEnter: record("enter"),
+				OnStay:  record("stay"),
+				OnExit:  record("exit"),
+			})
+
+			for i, pos := range test.positions {
+
+				event = ""
+				test.shape.SetPositionVec(pos)
+				space.Step()
+
+				if event != test.want[i] {
+					t.Errorf("step %d (at %v): got event %q, want %q", i, pos, event, test.want[i])
+				}
+
+				if contacts := space.Contacts(); (len(contacts) > 0) != (test.want[i] == "enter" || test.want[i] == "stay") {
+					t.Errorf("step %d (at %v): %d contacts", i, pos, len(contacts))
+				}
+
+			}
+
+		})
+
+	}
+
+}
+
+func TestSpaceStepContactFiltering(t *testing.T) {
+
+	space := NewSpace(640, 480, 16, 16)
+
+	a := NewRectangle(50, 50, 20, 20)
+	b := NewRectangle(55, 55, 20, 20)
+	c := NewRectangle(60, 60, 20, 20)
+	d := NewRectangle(65, 65, 20, 20)
+
+	e := NewRectangle(72, 72, 20, 20)
+
+	a.Ignore(b)
+	c.SetCollisionLayer(LayerNone)
+	d.SetActive(false)
+
+	space.Add(a, b, c, d, e)
+	space.Step()
+
+	// All of the Shapes overlap their neighbors, but a ignores b, c isn't on any layers, and d is inactive, so only b and e are in contact
+	contacts := space.Contacts()
+
+	if len(contacts) != 1 || contacts[0].ShapeA != b || contacts[0].ShapeB != e {
+		for _, contact := range contacts {
+			t.Errorf("got a contact between the Shapes at %v and %v", contact.ShapeA.Position(), contact.ShapeB.Position())
+		}
+		t.Fatalf("got %d contacts, want only the one between b and e", len(contacts))
+	}
+
+}
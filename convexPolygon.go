@@ -79,6 +79,10 @@ func (cp *ConvexPolygon) Clone() IShape {
 	newPoly.ShapeBase.space = nil
 	newPoly.ShapeBase.touchingCells = []*Cell{}
 	newPoly.ShapeBase.owner = newPoly
+	newPoly.ShapeBase.ignoredShapes = append(ShapeCollection{}, cp.ignoredShapes...)
+	newPoly.ShapeBase.passingThrough = nil
+	newPoly.ShapeBase.carrier = nil
+	newPoly.ShapeBase.riders = nil
 
 	newPoly.rotation = cp.rotation
 	newPoly.scale = cp.scale
@@ -143,16 +147,23 @@ func (cp *ConvexPolygon) Lines() []collidingLine {
 // Transformed returns the ConvexPolygon's points / vertices, transformed according to the ConvexPolygon's position.
 func (cp *ConvexPolygon) Transformed() []Vector {
 	transformed := []Vector{}
-	for _, point := range cp.Points {
-		p := Vector{point.X * cp.scale.X, point.Y * cp.scale.Y}
-		if cp.rotation != 0 {
-			p = p.Rotate(-cp.rotation)
-		}
-		transformed = append(transformed, Vector{p.X + cp.position.X, p.Y + cp.position.Y})
+	for i := range cp.Points {
+		transformed = append(transformed, cp.transformedPoint(i))
 	}
 	return transformed
 }
 
+// transformedPoint returns the ConvexPolygon's point at the given index, transformed according to the ConvexPolygon's position.
+// Unlike Transformed(), this doesn't allocate, so it's used in hot paths like line of sight tests.
+func (cp *ConvexPolygon) transformedPoint(i int) Vector {
+	point := cp.Points[i]
+	p := Vector{point.X * cp.scale.X, point.Y * cp.scale.Y}
+	if cp.rotation != 0 {
+		p = p.Rotate(-cp.rotation)
+	}
+	return Vector{p.X + cp.position.X, p.Y + cp.position.Y}
+}
+
 // Bounds returns two Vectors, comprising the top-left and bottom-right positions of the bounds of the
 // ConvexPolygon, post-transformation.
 func (cp *ConvexPolygon) Bounds() Bounds {
@@ -249,7 +260,9 @@ func (p *ConvexPolygon) Rotation() float64 {
 
 // SetRotation sets the rotation for the ConvexPolygon; note that the rotation goes counter-clockwise from 0 to pi, and then from -pi at 180 down, back to 0.
 // This rotation scheme follows the way math.Atan2() works.
+// Any Shapes the ConvexPolygon carries (see SetCarrier()) are rotated around the ConvexPolygon's position along with it.
 func (p *ConvexPolygon) SetRotation(radians float64) {
+	prev := p.rotation
 	p.rotation = radians
 	if p.rotation > math.Pi {
 		p.rotation -= math.Pi * 2
@@ -258,6 +271,7 @@ func (p *ConvexPolygon) SetRotation(radians float64) {
 	}
 	p.updateBounds()
 	p.update()
+	p.carryRiders(Vector{}, radians-prev)
 }
 
 // Rotate is a helper function to rotate a ConvexPolygon by the radians given.
@@ -285,13 +299,14 @@ func (p *ConvexPolygon) SetScaleVec(vec Vector) {
 
 // Intersection returns an IntersectionSet for the other Shape provided.
 // If no intersection is detected, the IntersectionSet returned is empty.
+// If either Shape is a sensor, the IntersectionSet is flagged as such and its MTV is zero.
 func (p *ConvexPolygon) Intersection(other IShape) IntersectionSet {
 
 	switch otherShape := other.(type) {
 	case *ConvexPolygon:
-		return convexConvexTest(p, otherShape)
+		return applySensor(convexConvexTest(p, otherShape), p, other)
 	case *Circle:
-		return convexCircleTest(p, otherShape)
+		return applySensor(convexCircleTest(p, otherShape), p, other)
 	}
 
 	// This should never happen
@@ -312,6 +327,7 @@ type ShapeLineTestSettings struct {
 	OnIntersect      func(set IntersectionSet, index, count int) bool
 	IncludeAllPoints bool  // Whether to cast lines from all points in the Shape (true), or just points from the leading edges (false, and the default). Only takes effect for ConvexPolygons.
 	Lines            []int // Which line indices to cast from. If unset (which is the default), then all vertices from all lines will be used.
+	IncludeSensors   bool  // Whether to test against sensor Shapes (see ShapeBase.SetSensor()); by default, sensors are ignored.
 }
 
 var lineTestResults []IntersectionSet
@@ -319,6 +335,7 @@ var lineTestVertices = newSet[Vector]()
 
 // ShapeLineTest conducts a line test from each vertex of the ConvexPolygon using the settings passed.
 // By default, lines are cast from each vertex of each leading edge in the ConvexPolygon.
+// Shapes that the ConvexPolygon can't collide with (e.g. Shapes it ignores; see ShapeBase.CanCollideWith()) are skipped.
 func (cp *ConvexPolygon) ShapeLineTest(settings ShapeLineTestSettings) bool {
 
 	lineTestResults = lineTestResults[:0]
@@ -368,10 +385,11 @@ func (cp *ConvexPolygon) ShapeLineTest(settings ShapeLineTestSettings) bool {
 		start := p.Sub(vu.Add(settings.StartOffset))
 
 		LineTest(LineTestSettings{
-			Start:        start,
-			End:          p.Add(settings.Vector),
-			TestAgainst:  settings.TestAgainst,
-			callingShape: cp,
+			Start:         start,
+			End:           p.Add(settings.Vector),
+			TestAgainst:   settings.TestAgainst,
+			Caster:        cp,
+			IgnoreSensors: !settings.IncludeSensors,
 			OnIntersect: func(set IntersectionSet, index, max int) bool {
 
 				// Consolidate hits together across multiple objects
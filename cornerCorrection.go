@@ -0,0 +1,113 @@
+package resolv
+
+import "math"
+
+// CornerCorrectionSettings is a struct of settings for ShapeBase.CorrectCorner().
+type CornerCorrectionSettings struct {
+	Motion   Vector  // The motion the Shape was attempting when it collided (i.e. its velocity). Only collisions that stop this motion are corrected.
+	MaxNudge float64 // The maximum distance the Shape can be nudged perpendicular to its motion to clear the obstacle (e.g. a few pixels).
+	// TestAgainst is the set of Shapes that the nudged Shape must not overlap. If TestAgainst is nil, the Shapes in the Cells near the Shape
+	// are used, filtered to the ones the Shape can collide with (see ShapeBase.CanCollideWith()).
+	TestAgainst ShapeIterator
+}
+
+// CorrectCorner attempts to resolve a collision by nudging the Shape sideways around the corner of the obstacle, rather than stopping it dead.
+// This is useful for top-down games (where clipping the corner of a wall by a pixel shouldn't stop the player) or platformers (where bonking
+// the corner of a ceiling when jumping shouldn't stop the jump).
+//
+// The given IntersectionSet should be the result of an intersection between the Shape and another Shape (for example, from an IntersectionTest()
+// callback). If the collision would stop the Shape's motion, but nudging the Shape perpendicular to the motion by at most MaxNudge would clear
+// the obstacle without overlapping anything else, the Shape is nudged and CorrectCorner returns true. Otherwise, the Shape isn't moved and
+// CorrectCorner returns false, in which case the IntersectionSet's MTV should be applied as usual:
+//
+//	shape.IntersectionTest(resolv.IntersectionTestSettings{
+//		TestAgainst: nearbyShapes,
+//		OnIntersect: func(set resolv.IntersectionSet) bool {
+//			if !shape.CorrectCorner(set, resolv.CornerCorrectionSettings{Motion: velocity, MaxNudge: 4}) {
+//				shape.MoveVec(set.MTV)
+//			}
+//			return true
+//		},
+//	})
+func (s *ShapeBase) CorrectCorner(set IntersectionSet, settings CornerCorrectionSettings) bool {
+
+	if set.IsEmpty() || set.OtherShape == nil || set.IsSensor || settings.MaxNudge <= 0 || settings.Motion.IsZero() {
+		return false
+	}
+
+	// The collision doesn't oppose the motion, so there's nothing to correct
+	if set.MTV.Dot(settings.Motion) >= 0 {
+		return false
+	}
+
+	perp := settings.Motion.Unit().Perp()
+
+	// Nudging the Shape by the overlap of the projections onto the perpendicular axis clears the obstacle entirely
+	proj := s.owner.Project(perp)
+	otherProj := set.OtherShape.Project(perp)
+
+	nudges := [2]Vector{
+		perp.Scale(otherProj.Max - proj.Min),
+		perp.Scale(otherProj.Min - proj.Max),
+	}
+
+	if nudges[1].MagnitudeSquared() < nudges[0].MagnitudeSquared() {
+		nudges[0], nudges[1] = nudges[1], nudges[0]
+	}
+
+	testAgainst := settings.TestAgainst
+
+	if testAgainst == nil {
+		if s.space == nil {
+			return false
+		}
+		cellSize := min(float64(s.space.cellWidth), float64(s.space.cellHeight))
+		testAgainst = s.owner.SelectTouchingCells(1 + int(math.Ceil(settings.MaxNudge/cellSize))).FilterShapes()
+	}
+
+	for _, nudge := range nudges {
+
+		if nudge.Magnitude() > settings.MaxNudge {
+			break
+		}
+
+		if s.isClearAt(nudge, testAgainst) {
+			s.owner.MoveVec(nudge)
+			return true
+		}
+
+	}
+
+	return false
+
+}
+
+// isClearAt returns whether the Shape, offset by the given Vector from its current position, would be clear of (not overlapping) the given Shapes.
+// One-way Shapes are ignored, as they only block Shapes moving into them.
+func (s *ShapeBase) isClearAt(offset Vector, shapes ShapeIterator) bool {
+
+	origin := s.position
+	s.position = origin.Add(offset)
+
+	defer func() { s.position = origin }()
+
+	clear := true
+
+	shapes.ForEach(func(other IShape) bool {
+
+		if other.IsOneWay() || !s.owner.CanCollideWith(other) {
+			return true
+		}
+
+		if isPenetrating(s.owner.Intersection(other)) {
+			clear = false
+			return false
+		}
+
+		return true
+
+	})
+
+	return clear
+
+}
@@ -0,0 +1,191 @@
+package dynamics
+
+import (
+	"math"
+
+	"github.com/solarlune/resolv"
+)
+
+// Body is a rigid body that wraps a resolv Shape, giving it mass, velocity, and rotation so that it can be simulated by a World.
+// The Body's center of mass is the Shape's position, and the Body rotates around it; for ConvexPolygons, the position should
+// be the center of the polygon (as it is for Shapes created with resolv.NewRectangle()).
+//
+// Angles and angular velocities in this package follow the usual mathematical convention, where a positive angle rotates +X towards +Y.
+// In screen-space (where +Y points down), that's clockwise.
+type Body struct {
+	Shape           resolv.IShape // The Shape the Body wraps.
+	Velocity        resolv.Vector // The linear velocity of the Body, in units per second.
+	AngularVelocity float64       // The angular velocity of the Body, in radians per second.
+	Restitution     float64       // How bouncy the Body is, ranging from 0 (not bouncy at all) to 1 (perfectly bouncy). Defaults to 0.
+	Friction        float64       // How much friction the Body's surface has; 0 is frictionless. Defaults to 0.5.
+	GravityScale    float64       // How much the World's gravity affects the Body. Defaults to 1.
+	LinearDamping   float64       // How quickly the Body's linear velocity decays, as a proportion per second. Defaults to 0.
+	AngularDamping  float64       // How quickly the Body's angular velocity decays, as a proportion per second. Defaults to 0.
+	FixedRotation   bool          // If the Body shouldn't rotate from collisions at all (e.g. for characters). Defaults to false.
+	Data            any           // Data is a field for any user data associated with the Body.
+
+	mass, invMass       float64
+	inertia, invInertia float64
+	angle               float64
+	force               resolv.Vector
+	torque              float64
+	world               *World
+}
+
+// NewBody creates a new Body wrapping the given Shape with the given mass. A mass of 0 (or less) makes the Body static (immovable).
+// The Body's moment of inertia is calculated from the Shape and the mass.
+func NewBody(shape resolv.IShape, mass float64) *Body {
+	body := &Body{
+		Shape:        shape,
+		Friction:     0.5,
+		GravityScale: 1,
+	}
+	body.SetMass(mass)
+	return body
+}
+
+// SetMass sets the mass of the Body, recalculating its moment of inertia from its Shape. A mass of 0 (or less) makes the Body static (immovable).
+func (b *Body) SetMass(mass float64) {
+
+	if mass <= 0 {
+		b.mass, b.invMass = 0, 0
+		b.inertia, b.invInertia = 0, 0
+		return
+	}
+
+	b.mass = mass
+	b.invMass = 1 / mass
+	b.SetInertia(shapeInertia(b.Shape, mass))
+
+}
+
+// Mass returns the mass of the Body; a static Body has a mass of 0.
+func (b *Body) Mass() float64 {
+	return b.mass
+}
+
+// SetInertia sets the moment of inertia of the Body directly (which is otherwise calculated from its Shape by SetMass()).
+// An inertia of 0 (or less) stops the Body from rotating.
+func (b *Body) SetInertia(inertia float64) {
+	if inertia <= 0 {
+		b.inertia, b.invInertia = 0, 0
+		return
+	}
+	b.inertia = inertia
+	b.invInertia = 1 / inertia
+}
+
+// Inertia returns the moment of inertia of the Body.
+func (b *Body) Inertia() float64 {
+	return b.inertia
+}
+
+// IsStatic returns whether the Body is static (i.e. it has no mass, and so is immovable).
+func (b *Body) IsStatic() bool {
+	return b.invMass == 0
+}
+
+// Angle returns how far the Body has rotated (in radians) since it was created.
+func (b *Body) Angle() float64 {
+	return b.angle
+}
+
+// World returns the World the Body is in, or nil if it isn't in one.
+func (b *Body) World() *World {
+	return b.world
+}
+
+// ApplyForce applies a force to the Body's center of mass for the next World.Step() call.
+func (b *Body) ApplyForce(force resolv.Vector) {
+	b.force = b.force.Add(force)
+}
+
+// ApplyForceAt applies a force to the Body at the given point in world-space for the next World.Step() call, which can also rotate the Body.
+func (b *Body) ApplyForceAt(force, point resolv.Vector) {
+	b.force = b.force.Add(force)
+	b.torque += cross(point.Sub(b.Shape.Position()), force)
+}
+
+// ApplyTorque applies a torque to the Body for the next World.Step() call.
+func (b *Body) ApplyTorque(torque float64) {
+	b.torque += torque
+}
+
+// ApplyImpulse applies an impulse (an instant change in momentum) to the Body at the given point in world-space.
+func (b *Body) ApplyImpulse(impulse, point resolv.Vector) {
+	b.Velocity = b.Velocity.Add(impulse.Scale(b.invMass))
+	if !b.FixedRotation {
+		b.AngularVelocity += b.invInertia * cross(point.Sub(b.Shape.Position()), impulse)
+	}
+}
+
+// VelocityAt returns the velocity of the given point in world-space as though it were attached to the Body.
+func (b *Body) VelocityAt(point resolv.Vector) resolv.Vector {
+	return b.Velocity.Add(crossScalar(b.AngularVelocity, point.Sub(b.Shape.Position())))
+}
+
+func (b *Body) inverseInertia() float64 {
+	if b.FixedRotation {
+		return 0
+	}
+	return b.invInertia
+}
+
+// rotate rotates the Body (and its Shape) by the given angle.
+func (b *Body) rotate(angle float64) {
+	b.angle += angle
+	if poly, ok := b.Shape.(*resolv.ConvexPolygon); ok {
+		// ConvexPolygons rotate the other way
+		poly.Rotate(-angle)
+	}
+}
+
+// shapeInertia returns the moment of inertia of the given Shape around its position with the given mass.
+func shapeInertia(shape resolv.IShape, mass float64) float64 {
+
+	switch s := shape.(type) {
+
+	case *resolv.Circle:
+		return mass * s.Radius() * s.Radius() / 2
+
+	case *resolv.ConvexPolygon:
+
+		points := s.Transformed()
+		pos := s.Position()
+
+		if len(points) >= 3 {
+
+			numerator := 0.0
+			denominator := 0.0
+
+			for i := range points {
+				p1 := points[i].Sub(pos)
+				p2 := points[(i+1)%len(points)].Sub(pos)
+				c := math.Abs(cross(p1, p2))
+				numerator += c * (p1.Dot(p1) + p1.Dot(p2) + p2.Dot(p2))
+				denominator += c
+			}
+
+			if denominator > 0 {
+				return mass * numerator / (6 * denominator)
+			}
+
+		}
+
+	}
+
+	bounds := shape.Bounds()
+	w, h := bounds.Width(), bounds.Height()
+	return mass * (w*w + h*h) / 12
+
+}
+
+// cross returns the 2D cross product of the two Vectors.
+func cross(a, b resolv.Vector) float64 {
+	return a.X*b.Y - a.Y*b.X
+}
+
+// crossScalar returns the cross product of a scalar (representing a rotation around the Z axis) and a Vector.
+func crossScalar(s float64, v resolv.Vector) resolv.Vector {
+	return resolv.NewVector(-s*v.Y, s*v.X)
+}
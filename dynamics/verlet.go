@@ -0,0 +1,601 @@
+package dynamics
+
+import (
+	"math"
+
+	"github.com/solarlune/resolv"
+)
+
+// Particle is a point mass simulated by a ParticleSystem using Verlet integration. Each Particle has a radius, and collides with
+// the Shapes in the ParticleSystem's Space as a Circle (Particles don't collide with each other, though).
+// Particles are useful for ropes, chains, banners, and debris.
+type Particle struct {
+	Position     resolv.Vector // The current position of the Particle.
+	PrevPosition resolv.Vector // The position of the Particle last Step(); the difference between the two is the Particle's velocity.
+	Mass         float64       // The mass of the Particle, which weighs how much constraints move it. A mass of 0 makes the Particle immovable. Defaults to 1.
+	// Friction is how much of the Particle's sideways velocity is lost when it collides with a Shape, ranging from 0 (frictionless) to 1. Defaults to 0.2.
+	Friction float64
+	Data     any // Data is a field for any user data associated with the Particle.
+	// OnCollide is an optional callback called each Step() for each Shape the Particle collides with.
+	OnCollide func(particle *Particle, set resolv.IntersectionSet)
+
+	collider *resolv.Circle
+	contacts []resolv.IntersectionSet // The latest IntersectionSet for each Shape the Particle collided with this Step().
+	normal   resolv.Vector
+}
+
+// NewParticle creates a new Particle at the given position with the given radius.
+func NewParticle(x, y, radius float64) *Particle {
+	return &Particle{
+		Position:     resolv.NewVector(x, y),
+		PrevPosition: resolv.NewVector(x, y),
+		Mass:         1,
+		Friction:     0.2,
+		collider:     resolv.NewCircle(x, y, radius),
+	}
+}
+
+// Radius returns the radius of the Particle.
+func (p *Particle) Radius() float64 {
+	return p.collider.Radius()
+}
+
+// SetRadius sets the radius of the Particle.
+func (p *Particle) SetRadius(radius float64) {
+	p.collider.SetRadius(radius)
+}
+
+// Collider returns the Circle the Particle uses to collide with Shapes. The Circle isn't added to the Space, but its collision layer,
+// mask, group, and ignore list (see resolv.ShapeBase.CanCollideWith()) can be set to control what the Particle collides with.
+func (p *Particle) Collider() *resolv.Circle {
+	return p.collider
+}
+
+// Velocity returns the velocity of the Particle, in units per Step().
+func (p *Particle) Velocity() resolv.Vector {
+	return p.Position.Sub(p.PrevPosition)
+}
+
+// SetVelocity sets the velocity of the Particle, in units per Step().
+func (p *Particle) SetVelocity(velocity resolv.Vector) {
+	p.PrevPosition = p.Position.Sub(velocity)
+}
+
+// Teleport moves the Particle to the given position without changing its velocity.
+func (p *Particle) Teleport(position resolv.Vector) {
+	vel := p.Velocity()
+	p.Position = position
+	p.PrevPosition = position.Sub(vel)
+}
+
+// IsColliding returns whether the Particle collided with any Shapes during the last Step().
+func (p *Particle) IsColliding() bool {
+	return len(p.contacts) > 0
+}
+
+// CollisionNormal returns the normal of the last surface the Particle collided with during the last Step().
+func (p *Particle) CollisionNormal() resolv.Vector {
+	return p.normal
+}
+
+func (p *Particle) inverseMass() float64 {
+	if p.Mass <= 0 {
+		return 0
+	}
+	return 1 / p.Mass
+}
+
+// Constraint is a constraint between Particles, solved by a ParticleSystem each Step(). Custom constraints can be made by implementing this interface.
+type Constraint interface {
+	Solve()         // Solve moves the constrained Particles to satisfy the constraint (or breaks the constraint).
+	IsBroken() bool // IsBroken returns whether the constraint is broken; broken constraints are removed from the ParticleSystem.
+}
+
+// DistanceConstraint keeps two Particles at a set distance from each other, like a link in a chain or a stick.
+type DistanceConstraint struct {
+	A, B   *Particle
+	Length float64 // The distance to keep the Particles at.
+	// Stiffness is how strongly the constraint is enforced, ranging from 0 (not at all) to 1 (completely). Defaults to 1.
+	Stiffness float64
+	// BreakStretch is how far the constraint can be stretched (as a proportion of Length) before it breaks; for example, a BreakStretch of 0.5
+	// breaks the constraint when the Particles are more than 1.5 times Length apart. A BreakStretch of 0 means the constraint is unbreakable. Defaults to 0.
+	BreakStretch float64
+	// MaxLengthOnly makes the constraint only keep the Particles from getting further than Length apart (like a rope), rather than exactly Length apart (like a stick).
+	MaxLengthOnly bool
+
+	broken bool
+}
+
+// NewDistanceConstraint creates a new DistanceConstraint between the two Particles, keeping them at their current distance.
+func NewDistanceConstraint(a, b *Particle) *DistanceConstraint {
+	return &DistanceConstraint{
+		A:         a,
+		B:         b,
+		Length:    a.Position.Distance(b.Position),
+		Stiffness: 1,
+	}
+}
+
+// Solve moves the constraint's Particles towards or away from each other so they're the constraint's Length apart.
+func (c *DistanceConstraint) Solve() {
+
+	if c.broken {
+		return
+	}
+
+	delta := c.B.Position.Sub(c.A.Position)
+	dist := delta.Magnitude()
+
+	if c.BreakStretch > 0 && dist > c.Length*(1+c.BreakStretch) {
+		c.broken = true
+		return
+	}
+
+	if dist == 0 || (c.MaxLengthOnly && dist <= c.Length) {
+		return
+	}
+
+	wA, wB := c.A.inverseMass(), c.B.inverseMass()
+	if wA+wB == 0 {
+		return
+	}
+
+	correction := delta.Scale((dist - c.Length) / dist * c.Stiffness / (wA + wB))
+	c.A.Position = c.A.Position.Add(correction.Scale(wA))
+	c.B.Position = c.B.Position.Sub(correction.Scale(wB))
+
+}
+
+// Break breaks the constraint.
+func (c *DistanceConstraint) Break() {
+	c.broken = true
+}
+
+// IsBroken returns whether the constraint is broken.
+func (c *DistanceConstraint) IsBroken() bool {
+	return c.broken
+}
+
+// PinConstraint pins a Particle to a position, like a nail holding up the end of a rope.
+type PinConstraint struct {
+	Particle *Particle
+	Position resolv.Vector // The position to pin the Particle to; this can be changed to move the pin.
+	// BreakDistance is how far the Particle can be pulled from the pin (before the pin is enforced) before the pin breaks.
+	// A BreakDistance of 0 means the pin is unbreakable. Defaults to 0.
+	BreakDistance float64
+
+	broken bool
+}
+
+// NewPinConstraint creates a new PinConstraint pinning the Particle to its current position.
+func NewPinConstraint(particle *Particle) *PinConstraint {
+	return &PinConstraint{
+		Particle: particle,
+		Position: particle.Position,
+	}
+}
+
+// Solve moves the constraint's Particle to the pin's position.
+func (c *PinConstraint) Solve() {
+
+	if c.broken {
+		return
+	}
+
+	if c.BreakDistance > 0 && c.Particle.Position.Distance(c.Position) > c.BreakDistance {
+		c.broken = true
+		return
+	}
+
+	c.Particle.Position = c.Position
+
+}
+
+// Break breaks the constraint.
+func (c *PinConstraint) Break() {
+	c.broken = true
+}
+
+// IsBroken returns whether the constraint is broken.
+func (c *PinConstraint) IsBroken() bool {
+	return c.broken
+}
+
+// AngleConstraint keeps the angle formed by three Particles (A, then B in the middle, then C) at a set angle, which makes a chain of Particles stiff.
+type AngleConstraint struct {
+	A, B, C *Particle
+	Angle   float64 // The angle (in radians) to keep between the line from B to A and the line from B to C.
+	// Stiffness is how strongly the constraint is enforced, ranging from 0 (not at all) to 1 (completely). Defaults to 0.5.
+	Stiffness float64
+	// BreakAngle is how far (in radians) the angle can be bent from Angle before the constraint breaks.
+	// A BreakAngle of 0 means the constraint is unbreakable. Defaults to 0.
+	BreakAngle float64
+
+	broken bool
+}
+
+// NewAngleConstraint creates a new AngleConstraint between the three Particles, keeping them at their current angle.
+func NewAngleConstraint(a, b, c *Particle) *AngleConstraint {
+	con := &AngleConstraint{
+		A:         a,
+		B:         b,
+		C:         c,
+		Stiffness: 0.5,
+	}
+	con.Angle = con.currentAngle()
+	return con
+}
+
+func (c *AngleConstraint) currentAngle() float64 {
+	a := c.A.Position.Sub(c.B.Position)
+	b := c.C.Position.Sub(c.B.Position)
+	return normalizeAngle(math.Atan2(b.Y, b.X) - math.Atan2(a.Y, a.X))
+}
+
+// Solve rotates the outer Particles of the constraint around the middle one so that they form the constraint's Angle.
+func (c *AngleConstraint) Solve() {
+
+	if c.broken {
+		return
+	}
+
+	diff := normalizeAngle(c.currentAngle() - c.Angle)
+
+	if c.BreakAngle > 0 && math.Abs(diff) > c.BreakAngle {
+		c.broken = true
+		return
+	}
+
+	wA, wC := c.A.inverseMass(), c.C.inverseMass()
+	if wA+wC == 0 || diff == 0 {
+		return
+	}
+
+	diff *= c.Stiffness
+
+	pivot := c.B.Position
+	c.A.Position = pivot.Add(c.A.Position.Sub(pivot).Rotate(diff * wA / (wA + wC)))
+	c.C.Position = pivot.Add(c.C.Position.Sub(pivot).Rotate(-diff * wC / (wA + wC)))
+
+}
+
+// Break breaks the constraint.
+func (c *AngleConstraint) Break() {
+	c.broken = true
+}
+
+// IsBroken returns whether the constraint is broken.
+func (c *AngleConstraint) IsBroken() bool {
+	return c.broken
+}
+
+// ParticleSystem simulates Particles and the Constraints between them using Verlet integration, colliding the Particles with the Shapes in a Space.
+type ParticleSystem struct {
+	Space      *resolv.Space // The Space containing the Shapes the Particles collide with. If nil, the Particles don't collide with anything.
+	Gravity    resolv.Vector // The gravity applied to each Particle each second. Defaults to {0, 980}, which is down in screen-space.
+	Damping    float64       // How much of the Particles' velocity is lost each Step(), ranging from 0 to 1. Defaults to 0.01.
+	Iterations int           // The number of times the Constraints and collisions are solved each Step(); more iterations make for stiffer ropes. Defaults to 8.
+	// OnBreak is an optional callback called when a Constraint breaks; the Constraint is removed from the ParticleSystem afterwards.
+	OnBreak func(constraint Constraint)
+
+	Particles   []*Particle
+	Constraints []Constraint
+}
+
+// NewParticleSystem creates a new ParticleSystem, colliding with the Shapes in the given Space (which can be nil).
+func NewParticleSystem(space *resolv.Space) *ParticleSystem {
+	return &ParticleSystem{
+		Space:      space,
+		Gravity:    resolv.NewVector(0, 980),
+		Damping:    0.01,
+		Iterations: 8,
+	}
+}
+
+// Add adds the given Particles to the ParticleSystem.
+func (ps *ParticleSystem) Add(particles ...*Particle) {
+	ps.Particles = append(ps.Particles, particles...)
+}
+
+// AddConstraints adds the given Constraints to the ParticleSystem.
+func (ps *ParticleSystem) AddConstraints(constraints ...Constraint) {
+	ps.Constraints = append(ps.Constraints, constraints...)
+}
+
+// Remove removes the given Particles from the ParticleSystem, along with any of the built-in Constraints that use them.
+func (ps *ParticleSystem) Remove(particles ...*Particle) {
+
+	for _, particle := range particles {
+
+		for i, p := range ps.Particles {
+			if p == particle {
+				ps.Particles[i] = nil
+				ps.Particles = append(ps.Particles[:i], ps.Particles[i+1:]...)
+				break
+			}
+		}
+
+		constraints := ps.Constraints[:0]
+
+		for _, c := range ps.Constraints {
+
+			uses := false
+
+			switch con := c.(type) {
+			case *DistanceConstraint:
+				uses = con.A == particle || con.B == particle
+			case *PinConstraint:
+				uses = con.Particle == particle
+			case *AngleConstraint:
+				uses = con.A == particle || con.B == particle || con.C == particle
+			}
+
+			if !uses {
+				constraints = append(constraints, c)
+			}
+
+		}
+
+		for i := len(constraints); i < len(ps.Constraints); i++ {
+			ps.Constraints[i] = nil
+		}
+
+		ps.Constraints = constraints
+
+	}
+
+}
+
+// RemoveConstraints removes the given Constraints from the ParticleSystem.
+func (ps *ParticleSystem) RemoveConstraints(constraints ...Constraint) {
+	for _, constraint := range constraints {
+		for i, c := range ps.Constraints {
+			if c == constraint {
+				ps.Constraints[i] = nil
+				ps.Constraints = append(ps.Constraints[:i], ps.Constraints[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// AddRope adds a rope (or chain) of Particles from start to end to the ParticleSystem, made of the given number of segments and linked with
+// DistanceConstraints. The Particles and the Constraints are returned; pin the ends (e.g. with a PinConstraint) to hang the rope up.
+func (ps *ParticleSystem) AddRope(start, end resolv.Vector, segments int, radius float64) ([]*Particle, []*DistanceConstraint) {
+
+	if segments < 1 {
+		segments = 1
+	}
+
+	particles := make([]*Particle, 0, segments+1)
+	constraints := make([]*DistanceConstraint, 0, segments)
+
+	for i := 0; i <= segments; i++ {
+
+		pos := start.Add(end.Sub(start).Scale(float64(i) / float64(segments)))
+		particle := NewParticle(pos.X, pos.Y, radius)
+		particles = append(particles, particle)
+		ps.Add(particle)
+
+		if i > 0 {
+			c := NewDistanceConstraint(particles[i-1], particle)
+			constraints = append(constraints, c)
+			ps.AddConstraints(c)
+		}
+
+	}
+
+	return particles, constraints
+
+}
+
+// AddGrid adds a grid of Particles to the ParticleSystem, linked to their horizontal and vertical neighbors with DistanceConstraints, like a
+// piece of cloth or a banner. The grid starts at the given top-left position, and the Particles are spaced apart by the given spacing.
+// The Particles are returned in rows (so the Particle at column x and row y is at index y*columns + x), along with the Constraints.
+func (ps *ParticleSystem) AddGrid(topLeft resolv.Vector, columns, rows int, spacing, radius float64) ([]*Particle, []*DistanceConstraint) {
+
+	particles := make([]*Particle, 0, columns*rows)
+	constraints := []*DistanceConstraint{}
+
+	for y := 0; y < rows; y++ {
+
+		for x := 0; x < columns; x++ {
+
+			particle := NewParticle(topLeft.X+float64(x)*spacing, topLeft.Y+float64(y)*spacing, radius)
+			particles = append(particles, particle)
+			ps.Add(particle)
+
+			if x > 0 {
+				c := NewDistanceConstraint(particles[len(particles)-2], particle)
+				constraints = append(constraints, c)
+				ps.AddConstraints(c)
+			}
+
+			if y > 0 {
+				c := NewDistanceConstraint(particles[len(particles)-1-columns], particle)
+				constraints = append(constraints, c)
+				ps.AddConstraints(c)
+			}
+
+		}
+
+	}
+
+	return particles, constraints
+
+}
+
+// Step steps the ParticleSystem's simulation forward by dt seconds: the Particles are moved by their velocities and gravity, and then the Constraints
+// and collisions with the Space's Shapes are solved. Broken Constraints are removed afterwards. For a stable simulation, Step should be called with a fixed dt.
+func (ps *ParticleSystem) Step(dt float64) {
+
+	gravity := ps.Gravity.Scale(dt * dt)
+
+	for _, p := range ps.Particles {
+
+		for i := range p.contacts {
+			p.contacts[i] = resolv.IntersectionSet{}
+		}
+		p.contacts = p.contacts[:0]
+		p.normal = resolv.Vector{}
+
+		if p.Mass <= 0 {
+			p.PrevPosition = p.Position
+			continue
+		}
+
+		vel := p.Velocity().Scale(1 - ps.Damping)
+		p.PrevPosition = p.Position
+		p.Position = p.Position.Add(vel).Add(gravity)
+
+		ps.sweep(p)
+
+	}
+
+	for i := 0; i < ps.Iterations; i++ {
+
+		for _, c := range ps.Constraints {
+			c.Solve()
+		}
+
+		for _, p := range ps.Particles {
+			ps.collide(p)
+		}
+
+	}
+
+	for _, p := range ps.Particles {
+
+		if len(p.contacts) == 0 {
+			continue
+		}
+
+		// Friction slows down the Particle's movement along the surface
+		vel := p.Velocity()
+		tangential := vel.Sub(p.normal.Scale(vel.Dot(p.normal)))
+		p.PrevPosition = p.PrevPosition.Add(tangential.Scale(p.Friction))
+
+		if p.OnCollide != nil {
+			for _, set := range p.contacts {
+				p.OnCollide(p, set)
+			}
+		}
+
+	}
+
+	constraints := ps.Constraints[:0]
+
+	for _, c := range ps.Constraints {
+		if c.IsBroken() {
+			if ps.OnBreak != nil {
+				ps.OnBreak(c)
+			}
+			continue
+		}
+		constraints = append(constraints, c)
+	}
+
+	for i := len(constraints); i < len(ps.Constraints); i++ {
+		ps.Constraints[i] = nil
+	}
+
+	ps.Constraints = constraints
+
+}
+
+// sweep stops the Particle at the first surface it would pass through while moving from its previous position to its current one.
+// Without this, fast or small Particles could tunnel through Shapes entirely between Steps.
+func (ps *ParticleSystem) sweep(p *Particle) {
+
+	motion := p.Position.Sub(p.PrevPosition)
+
+	if ps.Space == nil || motion.Magnitude() < p.Radius() {
+		return
+	}
+
+	dir := motion.Unit()
+	end := p.Position.Add(dir.Scale(p.Radius()))
+
+	bounds := resolv.Bounds{
+		Min: resolv.NewVector(math.Min(p.PrevPosition.X, end.X), math.Min(p.PrevPosition.Y, end.Y)),
+		Max: resolv.NewVector(math.Max(p.PrevPosition.X, end.X), math.Max(p.PrevPosition.Y, end.Y)),
+	}
+
+	resolv.LineTest(resolv.LineTestSettings{
+		Start:         p.PrevPosition,
+		End:           end,
+		TestAgainst:   ps.Space.FilterCells(bounds).FilterShapes(),
+		Caster:        p.collider,
+		IgnoreSensors: true,
+		OnIntersect: func(set resolv.IntersectionSet, index, max int) bool {
+			for _, inter := range set.Intersections {
+				// Skip surfaces the Particle is leaving, rather than entering
+				if inter.Normal.Dot(dir) < 0 {
+					p.Position = inter.Point.Sub(dir.Scale(p.Radius()))
+					return false
+				}
+			}
+			return true
+		},
+	})
+
+}
+
+// collide pushes the Particle out of any Shapes it's overlapping in the ParticleSystem's Space, recording the contacts.
+func (ps *ParticleSystem) collide(p *Particle) {
+
+	if ps.Space == nil || p.Mass <= 0 {
+		return
+	}
+
+	p.collider.SetPositionVec(p.Position)
+
+	ps.Space.FilterCells(p.collider.Bounds()).FilterShapes().ForEach(func(other resolv.IShape) bool {
+
+		if other.IsSensor() || !p.collider.CanCollideWith(other) {
+			return true
+		}
+
+		set := p.collider.Intersection(other)
+
+		if set.IsEmpty() || set.MTV.IsZero() {
+			return true
+		}
+
+		if other.IsOneWay() {
+			// One-way Shapes are only solid if the Particle is coming from the solid side
+			dir := other.OneWayDirection()
+			if p.Velocity().Dot(dir) > 0 || set.MTV.Dot(dir) <= 0 || set.MTV.Magnitude() > p.Radius() {
+				return true
+			}
+		}
+
+		p.Position = p.Position.Add(set.MTV)
+		p.collider.SetPositionVec(p.Position)
+		p.normal = set.MTV.Unit()
+
+		for i := range p.contacts {
+			if p.contacts[i].OtherShape == other {
+				p.contacts[i] = set
+				return true
+			}
+		}
+
+		p.contacts = append(p.contacts, set)
+
+		return true
+
+	})
+
+}
+
+// normalizeAngle wraps the given angle (in radians) to the range of -Pi to Pi.
+func normalizeAngle(angle float64) float64 {
+	for angle > math.Pi {
+		angle -= math.Pi * 2
+	}
+	for angle < -math.Pi {
+		angle += math.Pi * 2
+	}
+	return angle
+}
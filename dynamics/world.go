@@ -0,0 +1,435 @@
+// Package dynamics is an optional, simple rigid body physics simulation built on top of resolv's Shapes and collision detection.
+// resolv itself doesn't do physics - it only tells you when Shapes collide - but for simple things like bouncing crates or tumbling debris,
+// a World can move Bodies around and resolve the contacts between them for you.
+package dynamics
+
+import (
+	"math"
+
+	"github.com/solarlune/resolv"
+)
+
+// World simulates a collection of Bodies. The Bodies' Shapes should be added to a resolv Space, which the World uses to find
+// the contacts between them. Shapes in the Space without Bodies are treated as static (immovable) obstacles.
+// One-way Shapes (see resolv.ShapeBase.SetOneWay()) only collide with Bodies coming from their solid side; a Body that enters one from
+// any other side (for example, jumping up through a platform) passes through it until it's no longer overlapping it.
+type World struct {
+	Gravity    resolv.Vector // The gravity applied to each Body each second. Defaults to {0, 980}, which is down in screen-space.
+	Iterations int           // The number of iterations to solve contacts for each Step(); more iterations are more accurate, but slower. Defaults to 8.
+	// Slop is how far Bodies are allowed to overlap before they're pushed apart; allowing a little overlap keeps resting contacts stable.
+	// Defaults to 0.1.
+	Slop float64
+	// Correction is how much of the overlap between Bodies (beyond Slop) is corrected each Step(), ranging from 0 to 1. Defaults to 0.2.
+	Correction float64
+	// RestitutionThreshold is the minimum speed (in pixels per second) that Bodies have to be moving into each other at to bounce; slower contacts don't
+	// bounce, which keeps resting Bodies from jittering. It's never lower than the speed gravity adds each Step(). Defaults to 60.
+	RestitutionThreshold float64
+	// StaticFriction and StaticRestitution are the friction and restitution used for Shapes in the Space that don't have Bodies.
+	// They default to 0.5 and 0, respectively.
+	StaticFriction, StaticRestitution float64
+
+	bodies       []*Body
+	shapeBodies  map[resolv.IShape]*Body
+	contacts     []contact
+	checkedPairs resolv.Set[resolv.PairID]
+	impulses     map[resolv.PairID][2]accumulatedImpulse // The impulses applied to each contact last Step(), for warm starting.
+
+	passing, prevPassing resolv.Set[resolv.PairID] // The pairs of Bodies where one is passing through the other, one-way, Shape.
+}
+
+// accumulatedImpulse is the total impulse applied at a contact point in a Step().
+type accumulatedImpulse struct {
+	Normal, Tangent float64
+}
+
+// contact represents a contact between two Bodies to be resolved in a World.Step().
+type contact struct {
+	ID                    resolv.PairID
+	A, B                  *Body
+	Normal                resolv.Vector // The normal of the contact, pointing from A to B.
+	Depth                 float64
+	Friction, Restitution float64
+	Points                [2]contactPoint
+	PointCount            int
+}
+
+// contactPoint is a single point of a contact between two Bodies.
+type contactPoint struct {
+	RA, RB                  resolv.Vector // The offset of the point from the positions of Bodies A and B.
+	NormalMass, TangentMass float64
+	Bias                    float64
+	NormalImpulse           float64
+	TangentImpulse          float64
+}
+
+// NewWorld creates a new, empty World.
+func NewWorld() *World {
+	return &World{
+		Gravity:              resolv.NewVector(0, 980),
+		Iterations:           8,
+		Slop:                 0.1,
+		Correction:           0.2,
+		RestitutionThreshold: 60,
+		StaticFriction:       0.5,
+		shapeBodies:          map[resolv.IShape]*Body{},
+		checkedPairs:         resolv.Set[resolv.PairID]{},
+		passing:              resolv.Set[resolv.PairID]{},
+		prevPassing:          resolv.Set[resolv.PairID]{},
+		impulses:             map[resolv.PairID][2]accumulatedImpulse{},
+	}
+}
+
+// Add adds the given Bodies to the World. Note that the Bodies' Shapes should also be added to a resolv Space for them to collide.
+func (w *World) Add(bodies ...*Body) {
+	for _, body := range bodies {
+		if body.world != nil {
+			body.world.Remove(body)
+		}
+		body.world = w
+		w.bodies = append(w.bodies, body)
+		w.shapeBodies[body.Shape] = body
+	}
+}
+
+// Remove removes the given Bodies from the World.
+func (w *World) Remove(bodies ...*Body) {
+	for _, body := range bodies {
+		for i, b := range w.bodies {
+			if b == body {
+				w.bodies[i] = nil
+				w.bodies = append(w.bodies[:i], w.bodies[i+1:]...)
+				delete(w.shapeBodies, body.Shape)
+				body.world = nil
+				break
+			}
+		}
+	}
+}
+
+// Bodies returns a new slice consisting of the Bodies in the World.
+func (w *World) Bodies() []*Body {
+	return append(make([]*Body, 0, len(w.bodies)), w.bodies...)
+}
+
+// BodyOf returns the Body in the World that wraps the given Shape, or nil if there isn't one.
+func (w *World) BodyOf(shape resolv.IShape) *Body {
+	return w.shapeBodies[shape]
+}
+
+// Step steps the World's simulation forward by dt seconds: forces and gravity are applied to the Bodies, the contacts between them are
+// resolved using a sequential impulse solver, and then the Bodies (and their Shapes) are moved.
+// For a stable simulation, Step should be called with a fixed dt (e.g. 1.0 / 60.0).
+func (w *World) Step(dt float64) {
+
+	if dt <= 0 {
+		return
+	}
+
+	// Integrate forces
+	for _, body := range w.bodies {
+
+		if body.IsStatic() {
+			body.force = resolv.Vector{}
+			body.torque = 0
+			continue
+		}
+
+		body.Velocity = body.Velocity.Add(w.Gravity.Scale(body.GravityScale).Add(body.force.Scale(body.invMass)).Scale(dt))
+		body.AngularVelocity += body.torque * body.inverseInertia() * dt
+
+		body.Velocity = body.Velocity.Scale(1 / (1 + dt*body.LinearDamping))
+		body.AngularVelocity *= 1 / (1 + dt*body.AngularDamping)
+
+		body.force = resolv.Vector{}
+		body.torque = 0
+
+	}
+
+	w.findContacts()
+
+	w.prepareContacts(dt)
+
+	for i := 0; i < w.Iterations; i++ {
+		for c := range w.contacts {
+			w.contacts[c].solve()
+		}
+	}
+
+	for id := range w.impulses {
+		delete(w.impulses, id)
+	}
+
+	for _, c := range w.contacts {
+		var impulses [2]accumulatedImpulse
+		for i := 0; i < c.PointCount; i++ {
+			impulses[i] = accumulatedImpulse{Normal: c.Points[i].NormalImpulse, Tangent: c.Points[i].TangentImpulse}
+		}
+		w.impulses[c.ID] = impulses
+	}
+
+	// Integrate positions
+	for _, body := range w.bodies {
+
+		if body.IsStatic() {
+			continue
+		}
+
+		body.Shape.MoveVec(body.Velocity.Scale(dt))
+
+		if body.AngularVelocity != 0 && !body.FixedRotation {
+			body.rotate(body.AngularVelocity * dt)
+		}
+
+	}
+
+}
+
+// findContacts finds the contacts between the World's Bodies and any Shapes near them.
+func (w *World) findContacts() {
+
+	w.contacts = w.contacts[:0]
+	w.checkedPairs.Clear()
+
+	w.prevPassing, w.passing = w.passing, w.prevPassing
+	w.passing.Clear()
+
+	for _, body := range w.bodies {
+
+		if body.IsStatic() || body.Shape.Space() == nil || !body.Shape.IsActive() {
+			continue
+		}
+
+		body.Shape.SelectTouchingCells(1).FilterShapes().ForEach(func(other resolv.IShape) bool {
+
+			id := resolv.NewPairID(body.Shape, other)
+
+			if w.checkedPairs.Contains(id) {
+				return true
+			}
+
+			w.checkedPairs.Add(id)
+
+			if body.Shape.IsSensor() || other.IsSensor() {
+				return true
+			}
+
+			otherBody := w.shapeBodies[other]
+
+			if otherBody == nil {
+				otherBody = &Body{
+					Shape:       other,
+					Friction:    w.StaticFriction,
+					Restitution: w.StaticRestitution,
+				}
+			}
+
+			w.addContact(id, body, otherBody)
+
+			return true
+
+		})
+
+	}
+
+}
+
+// addContact adds a contact between the two Bodies if their Shapes are overlapping.
+func (w *World) addContact(id resolv.PairID, a, b *Body) {
+
+	set := a.Shape.Intersection(b.Shape)
+
+	if set.IsEmpty() || set.MTV.IsZero() {
+		return
+	}
+
+	if a.Shape.IsOneWay() || b.Shape.IsOneWay() {
+		mtv, ok := w.oneWayMTV(id, a, b)
+		if !ok {
+			return
+		}
+		set.MTV = mtv
+	}
+
+	// The MTV pushes A out of B, so the normal from A to B is opposite
+	normal := set.MTV.Unit().Invert()
+
+	c := contact{
+		ID:          id,
+		A:           a,
+		B:           b,
+		Normal:      normal,
+		Depth:       set.MTV.Magnitude(),
+		Friction:    math.Sqrt(a.Friction * b.Friction),
+		Restitution: math.Max(a.Restitution, b.Restitution),
+	}
+
+	// Use the two points furthest apart along the contact's tangent, as they're enough to keep Bodies stable
+	tangent := normal.Perp()
+
+	var points [2]resolv.Vector
+
+	if circle, ok := a.Shape.(*resolv.Circle); ok {
+		// A Circle only ever touches at a single point; using both points where it crosses the other Shape's edge would spin it
+		points[0] = circle.Position().Add(normal.Scale(circle.Radius()))
+		c.PointCount = 1
+	} else if circle, ok := b.Shape.(*resolv.Circle); ok {
+		points[0] = circle.Position().Sub(normal.Scale(circle.Radius()))
+		c.PointCount = 1
+	} else if len(set.Intersections) == 0 {
+		points[0] = set.Center
+		c.PointCount = 1
+	} else {
+
+		minDot, maxDot := math.Inf(1), math.Inf(-1)
+
+		for _, inter := range set.Intersections {
+			d := inter.Point.Dot(tangent)
+			if d < minDot {
+				minDot = d
+				points[0] = inter.Point
+			}
+			if d > maxDot {
+				maxDot = d
+				points[1] = inter.Point
+			}
+		}
+
+		c.PointCount = 2
+		if maxDot-minDot < 1e-6 {
+			c.PointCount = 1
+		}
+
+	}
+
+	for i := 0; i < c.PointCount; i++ {
+		c.Points[i] = contactPoint{
+			RA: points[i].Sub(a.Shape.Position()),
+			RB: points[i].Sub(b.Shape.Position()),
+		}
+	}
+
+	w.contacts = append(w.contacts, c)
+
+}
+
+// oneWayMTV returns the MTV pushing Body A out of Body B when one of their Shapes is a one-way Shape, and whether they collide at all.
+// They only collide if the other Body is on the one-way Shape's solid side (so it's no more than halfway through it in the one-way direction);
+// otherwise, it's passing through the one-way Shape, and keeps doing so until they stop overlapping.
+func (w *World) oneWayMTV(id resolv.PairID, a, b *Body) (resolv.Vector, bool) {
+
+	mover, oneWay, sign := a, b, 1.0
+	if !b.Shape.IsOneWay() {
+		mover, oneWay, sign = b, a, -1
+	}
+
+	dir := oneWay.Shape.OneWayDirection()
+	proj := mover.Shape.Project(dir)
+	depth := oneWay.Shape.Project(dir).Max - proj.Min
+
+	if w.prevPassing.Contains(id) || depth <= 0 || depth > (proj.Max-proj.Min)/2 {
+		w.passing.Add(id)
+		return resolv.Vector{}, false
+	}
+
+	return dir.Scale(depth * sign), true
+
+}
+
+// prepareContacts precalculates the effective masses and biases of the World's contacts, and warm starts them
+// by applying the impulses from the last Step() (which helps stacks of Bodies settle).
+func (w *World) prepareContacts(dt float64) {
+
+	// Resting Bodies pick up this much speed from gravity each Step(), and shouldn't bounce because of it
+	threshold := math.Max(w.RestitutionThreshold, w.Gravity.Magnitude()*dt*2)
+
+	for ci := range w.contacts {
+
+		c := &w.contacts[ci]
+		tangent := c.Normal.Perp()
+
+		invMassSum := c.A.invMass + c.B.invMass
+		invIA, invIB := c.A.inverseInertia(), c.B.inverseInertia()
+
+		for i := 0; i < c.PointCount; i++ {
+
+			p := &c.Points[i]
+
+			rnA, rnB := cross(p.RA, c.Normal), cross(p.RB, c.Normal)
+			p.NormalMass = 1 / (invMassSum + invIA*rnA*rnA + invIB*rnB*rnB)
+
+			rtA, rtB := cross(p.RA, tangent), cross(p.RB, tangent)
+			p.TangentMass = 1 / (invMassSum + invIA*rtA*rtA + invIB*rtB*rtB)
+
+			p.Bias = w.Correction / dt * math.Max(0, c.Depth-w.Slop)
+
+			// Bounce if the Bodies are moving into each other fast enough; this is measured before any impulses are applied below
+			if vn := c.relativeVelocity(p).Dot(c.Normal); vn < -threshold {
+				p.Bias = math.Max(p.Bias, -c.Restitution*vn)
+			}
+
+		}
+
+	}
+
+	// Warm starting is done after all of the bounces are measured, so the impulses don't affect them
+	for ci := range w.contacts {
+
+		c := &w.contacts[ci]
+		tangent := c.Normal.Perp()
+		prevImpulses, warm := w.impulses[c.ID]
+
+		if !warm {
+			continue
+		}
+
+		for i := 0; i < c.PointCount; i++ {
+			p := &c.Points[i]
+			p.NormalImpulse = prevImpulses[i].Normal
+			p.TangentImpulse = prevImpulses[i].Tangent
+			c.applyImpulse(p, c.Normal.Scale(p.NormalImpulse).Add(tangent.Scale(p.TangentImpulse)))
+		}
+
+	}
+
+}
+
+// relativeVelocity returns the velocity of Body B relative to Body A at the given contact point.
+func (c *contact) relativeVelocity(p *contactPoint) resolv.Vector {
+	return c.B.Velocity.Add(crossScalar(c.B.AngularVelocity, p.RB)).Sub(c.A.Velocity.Add(crossScalar(c.A.AngularVelocity, p.RA)))
+}
+
+// solve applies impulses to the contact's Bodies to stop them from moving into each other, and to apply friction.
+func (c *contact) solve() {
+
+	tangent := c.Normal.Perp()
+
+	for i := 0; i < c.PointCount; i++ {
+
+		p := &c.Points[i]
+
+		// Normal impulse; the accumulated impulse is clamped so that the Bodies are only ever pushed apart
+		vn := c.relativeVelocity(p).Dot(c.Normal)
+		lambda := p.NormalMass * (p.Bias - vn)
+		prev := p.NormalImpulse
+		p.NormalImpulse = math.Max(prev+lambda, 0)
+		c.applyImpulse(p, c.Normal.Scale(p.NormalImpulse-prev))
+
+		// Friction impulse, limited by the normal impulse
+		vt := c.relativeVelocity(p).Dot(tangent)
+		lambda = -p.TangentMass * vt
+		maxFriction := c.Friction * p.NormalImpulse
+		prev = p.TangentImpulse
+		p.TangentImpulse = math.Max(-maxFriction, math.Min(prev+lambda, maxFriction))
+		c.applyImpulse(p, tangent.Scale(p.TangentImpulse-prev))
+
+	}
+
+}
+
+// applyImpulse applies the given impulse to Body B at the contact point, and the opposite impulse to Body A.
+func (c *contact) applyImpulse(p *contactPoint, impulse resolv.Vector) {
+	c.A.Velocity = c.A.Velocity.Sub(impulse.Scale(c.A.invMass))
+	c.A.AngularVelocity -= c.A.inverseInertia() * cross(p.RA, impulse)
+	c.B.Velocity = c.B.Velocity.Add(impulse.Scale(c.B.invMass))
+	c.B.AngularVelocity += c.B.inverseInertia() * cross(p.RB, impulse)
+}
@@ -0,0 +1,59 @@
+package dynamics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/solarlune/resolv"
+)
+
+func TestWorldStep(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		shape    resolv.IShape
+		velocity resolv.Vector
+		wantY    float64
+	}{
+		{"box landing on the floor", resolv.NewRectangle(100, 200, 20, 20), resolv.Vector{}, 390},
+		{"ball bouncing on the floor", resolv.NewCircle(100, 200, 10), resolv.Vector{}, 390},
+		{"box landing on a one-way platform", resolv.NewRectangle(300, 200, 20, 20), resolv.Vector{}, 290},
+		{"box jumping up through a one-way platform", resolv.NewRectangle(300, 390, 20, 20), resolv.NewVector(0, -600), 290},
+		{"box jumping up into a one-way platform", resolv.NewRectangle(300, 390, 20, 20), resolv.NewVector(0, -400), 390},
+	}
+
+	for _, test := range tests {
+
+		t.Run(test.name, func(t *testing.T) {
+
+			space := resolv.NewSpace(640, 480, 16, 16)
+
+			floor := resolv.NewRectangleFromTopLeft(0, 400, 640, 20)
+			platform := resolv.NewRectangleFromTopLeft(200, 300, 200, 10)
+			platform.SetOneWay(resolv.NewVector(0, -1))
+
+			space.Add(floor, platform, test.shape)
+
+			world := NewWorld()
+			body := NewBody(test.shape, 1)
+			body.Restitution = 0.5
+			body.Velocity = test.velocity
+			world.Add(body)
+
+			for i := 0; i < 180; i++ {
+				world.Step(1.0 / 60)
+			}
+
+			if y := body.Shape.Position().Y; math.Abs(y-test.wantY) > 1 {
+				t.Errorf("the Body came to rest at a Y of %v, want %v", y, test.wantY)
+			}
+
+			if speed := body.Velocity.Magnitude(); speed > 1 {
+				t.Errorf("the Body is still moving at %v", speed)
+			}
+
+		})
+
+	}
+
+}
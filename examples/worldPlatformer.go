@@ -210,6 +210,7 @@ func (p *PlatformingPlayer) Update() {
 	nearbyShapes := p.Object.SelectTouchingCells(4).FilterShapes()
 
 	p.OnGround = false
+	p.Object.SetCarrier(nil) // Stop riding whatever we were standing on; if we're still standing on it, we'll start riding it again below
 
 	checkVec := resolv.NewVector(0, p.YSpeed) // Check downwards by the distance of movement speed
 
@@ -226,11 +227,12 @@ func (p *PlatformingPlayer) Update() {
 			if p.YSpeed >= 0 && set.Intersections[0].Normal.Y < 0 {
 				// If we're falling and landing on upward facing line
 
-				p.OnGround = true                 // Then set on ground to true
-				p.WallSliding = false             // And wallsliding to false
-				p.YSpeed = 0                      // Stop vertical movement
-				p.Object.MoveVec(set.MTV.SubY(2)) // Move to contact plus a bit of floating to not be flush with the ground so running up ramps is easier
-				return false                      // We can stop iterating past this
+				p.OnGround = true                   // Then set on ground to true
+				p.WallSliding = false               // And wallsliding to false
+				p.YSpeed = 0                        // Stop vertical movement
+				p.Object.MoveVec(set.MTV.SubY(2))   // Move to contact plus a bit of floating to not be flush with the ground so running up ramps is easier
+				p.Object.SetCarrier(set.OtherShape) // Ride the ground, so we move along with it if it's a moving platform
+				return false                        // We can stop iterating past this
 
 			} else if set.Intersections[0].Normal.Y > 0 && p.YSpeed < 0 && set.OtherShape.Tags().Has(TagSolidWall) {
 				// Jumping and bonking on downward-facing line and it's solid
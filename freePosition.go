@@ -0,0 +1,245 @@
+package resolv
+
+import (
+	"math"
+	"sort"
+)
+
+// FindFreePosition searches for the closest position to near (within maxRadius of it) where the given Shape wouldn't overlap any other Shapes
+// in the Space, which is useful for spawning or teleporting Shapes without them getting stuck in level geometry.
+// filter is an optional function to select which Shapes count as obstacles; if it's nil, all Shapes the Shape can collide with
+// (see ShapeBase.CanCollideWith()) are obstacles. Sensors are never obstacles.
+//
+// The search spirals outwards from near one ring of the Space's Cells at a time. If the Shape would only cover Cells without any obstacles in them
+// at the closest point of a Cell, that point is free without any further checks, so empty areas are skipped quickly. Cells entirely inside of an obstacle
+// are skipped as well, and only the remaining Cells near obstacles are sampled more finely (every half Cell or half of the Shape's size, whichever is smaller) and checked against the obstacles' Shapes.
+// The search stops as soon as no Cell further out could hold a closer position, and the closest position found is then refined towards near.
+// This means searching a large radius through crowded areas is slower than through empty ones. Shapes can stick out past the edges of the Space,
+// but Cells outside of it don't hold any Shapes, so positions where the Shape would cover any of those are checked against all of the Shapes in the Space instead.
+//
+// The returned position is for the Shape's position (i.e. where you would call SetPositionVec() to place the Shape); the Shape itself isn't moved.
+// If no free position is found, FindFreePosition returns false.
+func (s *Space) FindFreePosition(shape IShape, near Vector, maxRadius float64, filter func(other IShape) bool) (Vector, bool) {
+
+	if shape == nil {
+		return Vector{}, false
+	}
+
+	b := shape.base()
+	origin := b.position
+	defer func() { b.position = origin }()
+
+	isObstacle := func(other IShape) bool {
+		return other != shape && other.IsActive() && !other.IsSensor() && shape.CanCollideWith(other) && (filter == nil || filter(other))
+	}
+
+	// forEachObstacle calls the given function for each obstacle that could overlap the Shape at the given position, stopping if it returns false.
+	// Cells outside of the Space don't hold any Shapes (though Shapes can stick out past its edges), so if the Shape would cover any of them,
+	// all of the Shapes in the Space are checked instead.
+	forEachObstacle := func(pos Vector, forEach func(other IShape) bool) {
+
+		b.position = pos
+		bounds := shape.Bounds()
+		sel := s.FilterCells(bounds)
+
+		if sel.StartX < 0 || sel.StartY < 0 || sel.EndX >= s.WidthInCells() || sel.EndY >= s.HeightInCells() {
+			for _, other := range s.shapes {
+				if isObstacle(other) && other.Bounds().IsIntersecting(bounds) && !forEach(other) {
+					return
+				}
+			}
+			return
+		}
+
+		sel.ForEach(func(other IShape) bool {
+			return !isObstacle(other) || forEach(other)
+		})
+
+	}
+
+	// hasNearbyObstacles returns whether there are any obstacles near enough to the Shape at the given position that it could overlap them.
+	hasNearbyObstacles := func(pos Vector) bool {
+		found := false
+		forEachObstacle(pos, func(other IShape) bool {
+			found = true
+			return false
+		})
+		return found
+	}
+
+	isFree := func(pos Vector) bool {
+
+		free := true
+
+		forEachObstacle(pos, func(other IShape) bool {
+
+			// Intersection tests don't catch one Shape being entirely inside of the other, so that's checked for separately
+			if isPenetrating(shape.Intersection(other)) || containsPoint(other, shapeCenter(shape)) || containsPoint(shape, shapeCenter(other)) {
+				free = false
+				return false
+			}
+
+			return true
+
+		})
+
+		return free
+
+	}
+
+	if isFree(near) {
+		return near, true
+	}
+
+	cw, ch := float64(s.cellWidth), float64(s.cellHeight)
+
+	step := min(cw, ch) / 2
+	if bounds := shape.Bounds(); bounds.MinAxis() > 0 {
+		step = min(step, bounds.MinAxis()/2)
+	}
+	if step <= 0 {
+		step = 1
+	}
+
+	cx := int(math.Floor(near.X / cw))
+	cy := int(math.Floor(near.Y / ch))
+
+	best := Vector{}
+	bestDist := math.MaxFloat64
+	found := false
+
+	// closestInCell returns the closest point in the given Cell to near.
+	closestInCell := func(x, y int) Vector {
+		return Vector{
+			clamp(near.X, float64(x)*cw, float64(x+1)*cw),
+			clamp(near.Y, float64(y)*ch, float64(y+1)*ch),
+		}
+	}
+
+	// isCellBlocked returns whether the given Cell is entirely inside of an obstacle; as obstacles are convex, it is if an obstacle contains all of its corners.
+	isCellBlocked := func(x, y int) bool {
+
+		// Cells outside of the Space are empty, but Shapes can still stick out into them
+		shapes := s.shapes
+		if cell := s.Cell(x, y); cell != nil {
+			shapes = cell.Shapes
+		}
+
+		left, top := float64(x)*cw, float64(y)*ch
+
+		for _, other := range shapes {
+			if isObstacle(other) &&
+				containsPoint(other, Vector{left, top}) && containsPoint(other, Vector{left + cw, top}) &&
+				containsPoint(other, Vector{left, top + ch}) && containsPoint(other, Vector{left + cw, top + ch}) {
+				return true
+			}
+		}
+
+		return false
+
+	}
+
+	type cellPos struct {
+		X, Y int
+		Dist float64
+	}
+
+	ring := []cellPos{}
+	samples := []Vector{}
+
+	for k := 0; ; k++ {
+
+		// Every point in this ring (and those further out) is at least this far away from near
+		if k > 0 {
+			inner := k - 1
+			ringDist := min(
+				min(near.X-float64(cx-inner)*cw, float64(cx+inner+1)*cw-near.X),
+				min(near.Y-float64(cy-inner)*ch, float64(cy+inner+1)*ch-near.Y),
+			)
+			if ringDist > maxRadius || ringDist >= bestDist {
+				break
+			}
+		}
+
+		ring = ring[:0]
+
+		for y := cy - k; y <= cy+k; y++ {
+			for x := cx - k; x <= cx+k; x++ {
+				if x == cx-k || x == cx+k || y == cy-k || y == cy+k {
+					ring = append(ring, cellPos{x, y, closestInCell(x, y).Distance(near)})
+				}
+			}
+		}
+
+		sort.Slice(ring, func(i, j int) bool { return ring[i].Dist < ring[j].Dist })
+
+		for _, cell := range ring {
+
+			if cell.Dist > maxRadius || cell.Dist >= bestDist {
+				break
+			}
+
+			// If the Shape wouldn't even be near any obstacles at the closest point of the Cell, it's free
+			if p := closestInCell(cell.X, cell.Y); !hasNearbyObstacles(p) {
+				best, bestDist, found = p, cell.Dist, true
+				break
+			}
+
+			// Cells entirely inside of an obstacle (e.g. in the middle of a large wall) can't have any free positions
+			if isCellBlocked(cell.X, cell.Y) {
+				continue
+			}
+
+			// Otherwise, sample the Cell more finely
+			samples = samples[:0]
+			samples = append(samples, closestInCell(cell.X, cell.Y))
+
+			for y := float64(cell.Y) * ch; y < float64(cell.Y+1)*ch; y += step {
+				for x := float64(cell.X) * cw; x < float64(cell.X+1)*cw; x += step {
+					samples = append(samples, Vector{x, y})
+				}
+			}
+
+			sort.Slice(samples, func(i, j int) bool {
+				return samples[i].DistanceSquared(near) < samples[j].DistanceSquared(near)
+			})
+
+			for _, p := range samples {
+
+				dist := p.Distance(near)
+
+				if dist > maxRadius || dist >= bestDist {
+					break
+				}
+
+				if isFree(p) {
+					best, bestDist, found = p, dist, true
+					break
+				}
+
+			}
+
+		}
+
+	}
+
+	if !found {
+		return Vector{}, false
+	}
+
+	// Refine the position by moving it towards near for as long as it stays free
+	lo, hi := 0.0, 1.0
+	dir := best.Sub(near)
+
+	for i := 0; i < 16; i++ {
+		mid := (lo + hi) / 2
+		if isFree(near.Add(dir.Scale(mid))) {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+
+	return near.Add(dir.Scale(hi)), true
+
+}
@@ -0,0 +1,76 @@
+package resolv
+
+import (
+	"testing"
+)
+
+func TestFindFreePosition(t *testing.T) {
+
+	space := NewSpace(640, 480, 16, 16)
+
+	// A border wall sticking out past the left edge of the Space
+	border := NewRectangleFromTopLeft(-100, 0, 150, 480)
+	block := NewRectangleFromTopLeft(200, 200, 100, 100)
+	sensor := NewRectangleFromTopLeft(400, 200, 100, 100)
+	sensor.SetSensor(true)
+
+	space.Add(border, block, sensor)
+
+	tests := []struct {
+		name      string
+		near      Vector
+		maxRadius float64
+		filter    func(other IShape) bool
+		want      Vector
+		wantOK    bool
+	}{
+		{"already free", Vector{100, 100}, 100, nil, Vector{100, 100}, true},
+		{"inside of a block", Vector{210, 250}, 100, nil, Vector{195, 250}, true},
+		{"deep inside of a block", Vector{250, 215}, 100, nil, Vector{250, 195}, true},
+		{"inside of a wall past the edge of the Space", Vector{10, 100}, 100, nil, Vector{55, 100}, true},
+		{"entirely outside of the Space, inside of a wall", Vector{-50, 100}, 200, nil, Vector{-105, 100}, true},
+		{"too far away", Vector{250, 250}, 20, nil, Vector{}, false},
+		{"filtered out", Vector{250, 250}, 20, func(other IShape) bool { return other != block }, Vector{250, 250}, true},
+		{"inside of a sensor", Vector{450, 250}, 20, nil, Vector{450, 250}, true},
+	}
+
+	for _, test := range tests {
+
+		t.Run(test.name, func(t *testing.T) {
+
+			shape := NewRectangle(320, 20, 10, 10)
+			space.Add(shape)
+			defer space.Remove(shape)
+
+			pos, ok := space.FindFreePosition(shape, test.near, test.maxRadius, test.filter)
+
+			if ok != test.wantOK {
+				t.Fatalf("found a position = %v (%v), want %v", ok, pos, test.wantOK)
+			}
+
+			if !ok {
+				return
+			}
+
+			// The search samples positions in steps, so the position found isn't exactly the closest one
+			if pos.Sub(test.want).Magnitude() > 1 {
+				t.Errorf("position is %v, want %v", pos, test.want)
+			}
+
+			if want := (Vector{320, 20}); !shape.Position().Equals(want) {
+				t.Errorf("the Shape was moved to %v", shape.Position())
+			}
+
+			shape.SetPositionVec(pos)
+
+			for _, other := range []IShape{border, block} {
+				if (test.filter == nil || test.filter(other)) && (isPenetrating(shape.Intersection(other)) || containsPoint(other, shapeCenter(shape))) {
+					t.Errorf("the Shape overlaps %v at %v", other, pos)
+				}
+			}
+
+		})
+
+	}
+
+}
@@ -0,0 +1,106 @@
+package resolv
+
+import "math"
+
+// Layers represents one or more bitwise collision layers contained within a single uint64.
+// While Tags are used to identify what a Shape is (e.g. player, solid, ramp, platform, etc), Layers are used to
+// control what a Shape can collide with. Each Shape sits on one or more layers (its collision layer) and
+// collides with Shapes on one or more layers (its collision mask).
+// The maximum number of layers one can define is 64 (to match the uint size).
+type Layers uint64
+
+const (
+	LayerNone    Layers = 0              // No layers at all; a Shape with a collision mask of LayerNone collides with nothing.
+	LayerDefault Layers = 1              // The layer all Shapes are on by default.
+	LayerAll     Layers = math.MaxUint64 // All layers; this is the default collision mask for all Shapes.
+)
+
+// Has returns if the Layers object has any of the layers indicated by layers set.
+// Note that you can combine layers using the bitwise operator `|` (e.g. `LayerPlayer | LayerEnemy`).
+func (l Layers) Has(layers Layers) bool {
+	return l&layers > 0
+}
+
+// IsEmpty returns if the Layers object has no layers set.
+func (l Layers) IsEmpty() bool {
+	return l == 0
+}
+
+// SetLayersCollide sets whether Shapes on the given layers can collide with Shapes on the other given layers in the Space.
+// By default, all layers collide with all other layers. The collision matrix is symmetrical, so calling
+// SetLayersCollide(LayerA, LayerB, false) also stops Shapes on LayerB from colliding with Shapes on LayerA.
+// Note that you can combine layers using the bitwise operator `|` (e.g. `LayerPlayer | LayerEnemy`).
+func (s *Space) SetLayersCollide(layers, otherLayers Layers, collide bool) {
+
+	for i := 0; i < 64; i++ {
+
+		for j := 0; j < 64; j++ {
+
+			if !layers.Has(1<<i) || !otherLayers.Has(1<<j) {
+				continue
+			}
+
+			if collide {
+				s.layerMatrix[i] |= 1 << j
+				s.layerMatrix[j] |= 1 << i
+			} else {
+				s.layerMatrix[i] &^= 1 << j
+				s.layerMatrix[j] &^= 1 << i
+			}
+
+		}
+
+	}
+
+}
+
+// LayersCollide returns whether any of the given layers is allowed to collide with any of the other given layers in the Space,
+// according to the Space's collision matrix.
+func (s *Space) LayersCollide(layers, otherLayers Layers) bool {
+
+	for i := 0; i < 64; i++ {
+		if layers.Has(1<<i) && s.layerMatrix[i].Has(otherLayers) {
+			return true
+		}
+	}
+
+	return false
+
+}
+
+// CanCollideWith returns whether the Shape is allowed to collide with the other Shape, according to their collision layers, masks, and groups,
+// as well as the collision matrix of the Space the Shapes are in.
+// Shapes that share the same non-zero collision group always collide if the group is positive, and never collide if it is negative.
+// Otherwise, each Shape's collision layer must be present in the other Shape's collision mask, and the Space must allow the layers to collide.
+// Shapes that ignore each other (see ShapeBase.Ignore() and ShapeBase.SetIgnoreSameData()) never collide.
+// A Shape can't collide with itself, and inactive Shapes (see ShapeBase.SetActive()) can't collide with anything.
+func (s *ShapeBase) CanCollideWith(other IShape) bool {
+
+	if other == nil || other == s.owner || s.inactive || !other.IsActive() {
+		return false
+	}
+
+	if s.IsIgnoring(other) || other.IsIgnoring(s.owner) {
+		return false
+	}
+
+	if s.collisionGroup != 0 && s.collisionGroup == other.CollisionGroup() {
+		return s.collisionGroup > 0
+	}
+
+	if !s.collisionMask.Has(other.CollisionLayer()) || !other.CollisionMask().Has(s.collisionLayer) {
+		return false
+	}
+
+	space := s.space
+	if space == nil {
+		space = other.Space()
+	}
+
+	if space != nil && !space.LayersCollide(s.collisionLayer, other.CollisionLayer()) {
+		return false
+	}
+
+	return true
+
+}
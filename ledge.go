@@ -0,0 +1,232 @@
+package resolv
+
+import "math"
+
+// LedgeQuerySettings is a struct of settings for ShapeBase.CheckFloorAhead() and ShapeBase.FindLedge().
+type LedgeQuerySettings struct {
+	Facing Vector // The direction the Shape is facing; only the sideways part of it (relative to Up) is used.
+	// Up is the up direction. If Up is a zero Vector, it defaults to {0, -1}, which is up in screen-space (where +Y points down).
+	Up Vector
+	// LookAhead is how far ahead of the front of the Shape to check for floor in CheckFloorAhead(). If LookAhead is 0 or less, it defaults to 1.
+	LookAhead float64
+	// DropDistance is how far below the bottom of the Shape floor can be for it to count in CheckFloorAhead().
+	// If DropDistance is 0 or less, it defaults to the height of the Shape.
+	DropDistance float64
+	// GrabDistance is how far ahead of the front of the Shape a ledge can be to be grabbed in FindLedge(). If GrabDistance is 0 or less, it defaults to 4.
+	GrabDistance float64
+	// GrabHeight is how far above or below the top of the Shape a ledge can be to be grabbed in FindLedge(). If GrabHeight is 0 or less, it defaults to 4.
+	GrabHeight float64
+	// Clearance is how much free space there must be above a ledge for it to be grabbable in FindLedge().
+	// If Clearance is 0 or less, it defaults to the height of the Shape.
+	Clearance float64
+	// TestAgainst is the set of Shapes to test against. If TestAgainst is nil, the Shapes in the Cells near the Shape are used.
+	// Either way, Shapes that the Shape can't collide with (see ShapeBase.CanCollideWith()) and sensors are skipped.
+	TestAgainst ShapeIterator
+}
+
+// FloorAhead describes the floor found ahead of a Shape by ShapeBase.CheckFloorAhead().
+type FloorAhead struct {
+	Shape  IShape  // The Shape the floor belongs to.
+	Point  Vector  // The point on the floor directly ahead of the Shape.
+	Normal Vector  // The normal of the floor.
+	Drop   float64 // How far below the bottom of the Shape the floor is.
+}
+
+// Ledge describes a grabbable ledge found by ShapeBase.FindLedge().
+type Ledge struct {
+	Shape      IShape // The Shape the ledge belongs to.
+	Corner     Vector // The corner of the ledge (where the wall meets the top surface).
+	WallNormal Vector // The normal of the ledge's wall (facing the Shape).
+	Normal     Vector // The normal of the ledge's top surface.
+}
+
+// CheckFloorAhead checks for floor just ahead of the front of the Shape (in the direction it's facing), within the drop distance below the bottom of it.
+// If there's floor, CheckFloorAhead returns it and true; otherwise, it returns false. This is useful for AI that shouldn't walk off of platforms,
+// for example.
+func (s *ShapeBase) CheckFloorAhead(settings LedgeQuerySettings) (FloorAhead, bool) {
+
+	q, ok := s.newLedgeQuery(settings)
+	if !ok {
+		return FloorAhead{}, false
+	}
+
+	lookAhead := settings.LookAhead
+	if lookAhead <= 0 {
+		lookAhead = 1
+	}
+
+	drop := settings.DropDistance
+	if drop <= 0 {
+		drop = q.height()
+	}
+
+	start := q.point(q.front.Max+lookAhead, q.vertical.Min)
+
+	hit, ok := q.lineHit(start, start.Add(q.up.Scale(-drop)))
+
+	if !ok || hit.Normal.Dot(q.up) <= 0 {
+		return FloorAhead{}, false
+	}
+
+	return FloorAhead{
+		Shape:  hit.Shape,
+		Point:  hit.Point,
+		Normal: hit.Normal,
+		Drop:   max(start.Sub(hit.Point).Dot(q.up), 0),
+	}, true
+
+}
+
+// FindLedge looks for a grabbable ledge in front of the Shape (in the direction it's facing) near the top of the Shape.
+// A ledge is the top corner of a wall with walkable ground on top and free space above it. If a ledge is found, FindLedge returns it and true;
+// otherwise, it returns false. This is useful for ledge-grabbing in platformers, for example.
+func (s *ShapeBase) FindLedge(settings LedgeQuerySettings) (Ledge, bool) {
+
+	q, ok := s.newLedgeQuery(settings)
+	if !ok {
+		return Ledge{}, false
+	}
+
+	grabDistance := settings.GrabDistance
+	if grabDistance <= 0 {
+		grabDistance = 4
+	}
+
+	grabHeight := settings.GrabHeight
+	if grabHeight <= 0 {
+		grabHeight = 4
+	}
+
+	clearance := settings.Clearance
+	if clearance <= 0 {
+		clearance = q.height()
+	}
+
+	// Look for the top of the ledge by looking downwards just ahead of the Shape
+	start := q.point(q.front.Max+grabDistance, q.vertical.Max+grabHeight)
+	top, ok := q.lineHit(start, start.Add(q.up.Scale(-grabHeight*2)))
+
+	if !ok || top.Normal.Dot(q.up) < math.Cos(ToRadians(45)) {
+		return Ledge{}, false
+	}
+
+	level := top.Point.Dot(q.up)
+
+	// Look for the wall under the top of the ledge
+	center := q.front.Min + (q.front.Max-q.front.Min)/2
+	start = q.point(center, level-0.5)
+	wall, ok := q.lineHit(start, q.point(q.front.Max+grabDistance, level-0.5))
+
+	if !ok || wall.Normal.Dot(q.forward) > -math.Cos(ToRadians(45)) {
+		return Ledge{}, false
+	}
+
+	corner := q.point(wall.Point.Dot(q.forward), level)
+
+	// The path from the Shape to the corner, as well as the space above the corner, must be free
+	start = q.point(center, level+0.5)
+	if _, blocked := q.lineHit(start, corner.Add(q.forward.Scale(0.5)).Add(q.up.Scale(0.5))); blocked {
+		return Ledge{}, false
+	}
+
+	start = corner.Add(q.forward.Scale(0.5))
+	if _, blocked := q.lineHit(start, start.Add(q.up.Scale(clearance))); blocked {
+		return Ledge{}, false
+	}
+
+	return Ledge{
+		Shape:      wall.Shape,
+		Corner:     corner,
+		WallNormal: wall.Normal,
+		Normal:     top.Normal,
+	}, true
+
+}
+
+// ledgeQuery holds the state for a ledge or floor-ahead query.
+type ledgeQuery struct {
+	shape           IShape
+	up, forward     Vector
+	front, vertical Projection // The Shape's extents along the forward and up directions.
+	testAgainst     ShapeIterator
+}
+
+// ledgeHit is a hit found by a ledgeQuery's line test.
+type ledgeHit struct {
+	Shape  IShape
+	Point  Vector
+	Normal Vector
+}
+
+func (s *ShapeBase) newLedgeQuery(settings LedgeQuerySettings) (ledgeQuery, bool) {
+
+	up := settings.Up.Unit()
+	if up.IsZero() {
+		up = NewVector(0, -1)
+	}
+
+	forward := settings.Facing.Sub(up.Scale(settings.Facing.Dot(up))).Unit()
+	if forward.IsZero() {
+		return ledgeQuery{}, false
+	}
+
+	q := ledgeQuery{
+		shape:       s.owner,
+		up:          up,
+		forward:     forward,
+		front:       s.owner.Project(forward),
+		vertical:    s.owner.Project(up),
+		testAgainst: settings.TestAgainst,
+	}
+
+	if q.testAgainst == nil {
+		if s.space == nil {
+			return ledgeQuery{}, false
+		}
+		// Generous enough to cover any of the lines tested, whatever the settings
+		reach := q.height() + settings.LookAhead + settings.DropDistance + settings.GrabDistance + settings.GrabHeight + settings.Clearance + 4
+		cellSize := min(float64(s.space.cellWidth), float64(s.space.cellHeight))
+		q.testAgainst = s.owner.SelectTouchingCells(1 + int(math.Ceil(reach/cellSize))).FilterShapes()
+	}
+
+	return q, true
+
+}
+
+// height returns the height of the Shape along the up direction.
+func (q ledgeQuery) height() float64 {
+	return q.vertical.Max - q.vertical.Min
+}
+
+// point returns the point at the given distances along the forward and up directions.
+func (q ledgeQuery) point(forward, up float64) Vector {
+	return q.forward.Scale(forward).Add(q.up.Scale(up))
+}
+
+// lineHit returns the closest surface hit by a line from start to end that faces the line (so surfaces the line starts inside of are skipped).
+func (q ledgeQuery) lineHit(start, end Vector) (ledgeHit, bool) {
+
+	dir := end.Sub(start)
+	hit := ledgeHit{}
+	found := false
+
+	LineTest(LineTestSettings{
+		Start:         start,
+		End:           end,
+		TestAgainst:   q.testAgainst,
+		Caster:        q.shape,
+		IgnoreSensors: true,
+		OnIntersect: func(set IntersectionSet, index, max int) bool {
+			for _, inter := range set.Intersections {
+				if inter.Normal.Dot(dir) < 0 && (!found || inter.Point.DistanceSquared(start) < hit.Point.DistanceSquared(start)) {
+					hit = ledgeHit{Shape: set.OtherShape, Point: inter.Point, Normal: inter.Normal}
+					found = true
+				}
+			}
+			return true
+		},
+	})
+
+	return hit, found
+
+}
@@ -0,0 +1,111 @@
+package resolv
+
+import "math"
+
+// Velocity returns how far the Shape moved between the last two Space.Step() calls (so, essentially, its velocity per frame).
+// The velocity is tracked from the Shape's position changes, so it includes any movement from being carried by another Shape (see SetCarrier()).
+func (s *ShapeBase) Velocity() Vector {
+	return s.velocity
+}
+
+// AngularVelocity returns how far (in radians) the Shape rotated between the last two Space.Step() calls.
+// Only ConvexPolygons can rotate, so this is always 0 for Circles.
+func (s *ShapeBase) AngularVelocity() float64 {
+	return s.angularVelocity
+}
+
+// VelocityAt returns the velocity of the given point as though it were attached to the Shape, taking into account both the
+// Shape's velocity and its angular velocity. This is useful for inheriting velocity from a moving or rotating platform when jumping off of it, for example.
+func (s *ShapeBase) VelocityAt(point Vector) Vector {
+	offset := point.Sub(s.position)
+	return s.velocity.Add(offset.Rotate(-s.angularVelocity).Sub(offset))
+}
+
+// SetCarrier sets the Shape's carrier - whenever the carrier moves or rotates, the Shape is moved along with it
+// (like a character riding a moving platform, or a box attached to a rotating wheel). When the carrier rotates, the Shape's position is
+// rotated around the carrier's, but the Shape itself isn't rotated. Passing nil detaches the Shape from its carrier.
+// A Shape can only have one carrier at a time, and a Shape can't be carried by a Shape it (directly or indirectly) carries.
+// If this would happen, SetCarrier does nothing and returns false; otherwise, it returns true.
+func (s *ShapeBase) SetCarrier(carrier IShape) bool {
+
+	if carrier == s.carrier {
+		return true
+	}
+
+	for c := carrier; c != nil; c = c.Carrier() {
+		if c == s.owner {
+			return false
+		}
+	}
+
+	if s.carrier != nil {
+		cb := s.carrier.base()
+		for i, rider := range cb.riders {
+			if rider == s.owner {
+				cb.riders[i] = nil
+				cb.riders = append(cb.riders[:i], cb.riders[i+1:]...)
+				break
+			}
+		}
+	}
+
+	s.carrier = carrier
+
+	if carrier != nil {
+		cb := carrier.base()
+		cb.riders = append(cb.riders, s.owner)
+	}
+
+	return true
+
+}
+
+// Carrier returns the Shape's carrier (the Shape that carries it when it moves); see SetCarrier(). If the Shape has no carrier, this returns nil.
+func (s *ShapeBase) Carrier() IShape {
+	return s.carrier
+}
+
+// Riders returns a new ShapeCollection consisting of the Shapes carried by the Shape; see SetCarrier().
+func (s *ShapeBase) Riders() ShapeCollection {
+	return append(make(ShapeCollection, 0, len(s.riders)), s.riders...)
+}
+
+// carryRiders moves the Shape's riders along with the Shape after it has moved by the given delta and rotated by the given angle (in radians) around its position.
+func (s *ShapeBase) carryRiders(delta Vector, rotation float64) {
+
+	for _, rider := range s.riders {
+
+		if rotation != 0 {
+			offset := rider.Position().Add(delta).Sub(s.position)
+			rider.SetPositionVec(s.position.Add(offset.Rotate(-rotation)))
+		} else if !delta.IsZero() {
+			rider.MoveVec(delta)
+		}
+
+	}
+
+}
+
+// updateVelocity updates the Shape's velocity and angular velocity from the change in its position and rotation since the last call.
+func (s *ShapeBase) updateVelocity() {
+
+	rotation := 0.0
+	if cp, ok := s.owner.(*ConvexPolygon); ok {
+		rotation = cp.rotation
+	}
+
+	if s.motionTracked {
+		s.velocity = s.position.Sub(s.lastPosition)
+		s.angularVelocity = rotation - s.lastRotation
+		if s.angularVelocity > math.Pi {
+			s.angularVelocity -= math.Pi * 2
+		} else if s.angularVelocity < -math.Pi {
+			s.angularVelocity += math.Pi * 2
+		}
+	}
+
+	s.lastPosition = s.position
+	s.lastRotation = rotation
+	s.motionTracked = true
+
+}
@@ -0,0 +1,134 @@
+package resolv
+
+import (
+	"math"
+	"testing"
+)
+
+func TestVelocityTracking(t *testing.T) {
+
+	space := NewSpace(640, 480, 16, 16)
+
+	platform := NewRectangle(100, 100, 40, 10)
+	space.Add(platform)
+
+	// Nothing's known about the Shape's motion before the first Step()
+	platform.Move(10, 0)
+	space.Step()
+
+	if !platform.Velocity().IsZero() {
+		t.Errorf("velocity after the first Step() is %v, want zero", platform.Velocity())
+	}
+
+	platform.Move(3, 4)
+	platform.Rotate(0.1)
+	space.Step()
+
+	if want := (Vector{3, 4}); !platform.Velocity().Equals(want) || math.Abs(platform.AngularVelocity()-0.1) > 1e-9 {
+		t.Errorf("velocity is %v at %v radians, want %v at 0.1 radians", platform.Velocity(), platform.AngularVelocity(), want)
+	}
+
+	// A point on the right end of the platform also moves with its rotation
+	point := platform.Position().Add(Vector{20, 0})
+	if want := (Vector{3, 4}).Add(Vector{20, 0}.Rotate(-0.1).Sub(Vector{20, 0})); !platform.VelocityAt(point).Equals(want) {
+		t.Errorf("VelocityAt() is %v, want %v", platform.VelocityAt(point), want)
+	}
+
+	space.Step()
+
+	if !platform.Velocity().IsZero() || platform.AngularVelocity() != 0 {
+		t.Errorf("velocity after not moving is %v at %v radians, want zero", platform.Velocity(), platform.AngularVelocity())
+	}
+
+}
+
+func TestCarrying(t *testing.T) {
+
+	platform := NewRectangle(100, 100, 40, 10)
+	rider := NewRectangle(110, 90, 10, 10)
+	passenger := NewCircle(110, 80, 5)
+
+	if !rider.SetCarrier(platform) || !passenger.SetCarrier(rider) {
+		t.Fatalf("couldn't set carriers")
+	}
+
+	// A Shape can't carry its own carrier
+	if platform.SetCarrier(passenger) {
+		t.Errorf("platform was allowed to be carried by a Shape it carries")
+	}
+
+	platform.Move(5, -5)
+
+	if want := (Vector{115, 85}); !rider.Position().Equals(want) {
+		t.Errorf("rider moved to %v, want %v", rider.Position(), want)
+	}
+
+	if want := (Vector{115, 75}); !passenger.Position().Equals(want) {
+		t.Errorf("passenger moved to %v, want %v", passenger.Position(), want)
+	}
+
+	// Rotating the platform swings the rider around it, without rotating the rider itself
+	platform.Rotate(math.Pi / 2)
+
+	if want := platform.Position().Add(Vector{10, -10}.Rotate(-math.Pi / 2)); !rider.Position().Equals(want) {
+		t.Errorf("rider rotated to %v, want %v", rider.Position(), want)
+	}
+
+	if rider.Rotation() != 0 {
+		t.Errorf("rider was rotated by %v radians", rider.Rotation())
+	}
+
+	rider.SetCarrier(nil)
+	platform.Move(5, 0)
+
+	if len(platform.Riders()) != 0 || rider.Carrier() != nil {
+		t.Errorf("rider wasn't detached")
+	}
+
+	if want := platform.Position().Add(Vector{-5, 0}).Add(Vector{10, -10}.Rotate(-math.Pi / 2)); !rider.Position().Equals(want) {
+		t.Errorf("detached rider moved to %v, want %v", rider.Position(), want)
+	}
+
+}
+
+func TestCharacterControllerRidingPlatforms(t *testing.T) {
+
+	space := NewSpace(640, 480, 16, 16)
+
+	platform := NewRectangle(100, 100, 60, 10)
+	space.Add(platform)
+
+	player := NewRectangle(100, 85, 20, 20)
+	space.Add(player)
+
+	cc := NewCharacterController(player)
+	cc.InheritPlatformVelocity = true
+
+	cc.Move(Vector{0, 1})
+
+	if cc.Platform() != platform || player.Carrier() != platform {
+		t.Fatalf("isn't riding the platform")
+	}
+
+	for i := 0; i < 3; i++ {
+		platform.Move(4, 0)
+		space.Step()
+		cc.Move(Vector{0, 1})
+	}
+
+	if want := (Vector{112, 85 - cc.Margin}); player.Position().Sub(want).Magnitude() > cc.Margin {
+		t.Errorf("moved to %v with the platform, want %v", player.Position(), want)
+	}
+
+	// Jumping off keeps the platform's speed
+	velocity := cc.Move(Vector{0, -10})
+
+	if player.Carrier() != nil || cc.Platform() != nil {
+		t.Errorf("is still riding the platform after jumping off")
+	}
+
+	if want := (Vector{4, -10}); !velocity.Equals(want) {
+		t.Errorf("velocity after jumping off is %v, want %v", velocity, want)
+	}
+
+}
@@ -0,0 +1,201 @@
+package resolv
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+)
+
+// NearestResult is a Shape found by Space.Nearest() or Space.NearestSurface().
+type NearestResult struct {
+	Shape    IShape  // The Shape found.
+	Distance float64 // The distance from the query point to the Shape.
+	Point    Vector  // The point on the Shape that the distance was measured to (its center for Nearest(), or the closest point on its surface for NearestSurface()).
+}
+
+// nearestHeap is a max-heap of the closest results found so far, so the furthest of them can be quickly checked and replaced.
+type nearestHeap []NearestResult
+
+func (h nearestHeap) Len() int            { return len(h) }
+func (h nearestHeap) Less(i, j int) bool  { return h[i].Distance > h[j].Distance }
+func (h nearestHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *nearestHeap) Push(x interface{}) { *h = append(*h, x.(NearestResult)) }
+func (h *nearestHeap) Pop() interface{} {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
+
+var nearestVisited = newSet[IShape]()
+
+// Nearest returns up to k Shapes in the Space closest to the given point, sorted from closest to furthest, measuring the distance to each Shape's center
+// (its position for Circles, and the center of its Bounds for ConvexPolygons). filter is an optional function to select which Shapes can be returned
+// (for example, only enemies); if it's nil, all Shapes can be returned. Inactive Shapes are skipped.
+//
+// The search expands outwards from the point's Cell one ring of Cells at a time, and stops as soon as no Shape in the remaining Cells could be closer
+// than the k results already found, so it only looks through as much of the Space as it has to.
+func (s *Space) Nearest(point Vector, k int, filter func(shape IShape) bool) []NearestResult {
+	return s.nearest(point, k, filter, false)
+}
+
+// NearestSurface returns up to k Shapes in the Space closest to the given point, sorted from closest to furthest, measuring the distance to the closest point
+// on each Shape's surface (which is 0 for Shapes the point is inside of). This is useful for finding the nearest wall, for example, as a large Shape's center
+// can be far away even when its surface is right next to the point. It otherwise works like Nearest().
+func (s *Space) NearestSurface(point Vector, k int, filter func(shape IShape) bool) []NearestResult {
+	return s.nearest(point, k, filter, true)
+}
+
+func (s *Space) nearest(point Vector, k int, filter func(shape IShape) bool, surface bool) []NearestResult {
+
+	if k <= 0 || len(s.cells) == 0 {
+		return nil
+	}
+
+	cw, ch := float64(s.cellWidth), float64(s.cellHeight)
+
+	cx := int(math.Floor(point.X / cw))
+	cy := int(math.Floor(point.Y / ch))
+
+	// The furthest ring that could still contain Cells in the Space
+	maxRing := max(max(float64(cx), float64(s.WidthInCells()-1-cx)), max(float64(cy), float64(s.HeightInCells()-1-cy)))
+
+	results := nearestHeap{}
+	nearestVisited.Clear()
+
+	visit := func(x, y int) {
+
+		cell := s.Cell(x, y)
+
+		if cell == nil {
+			return
+		}
+
+		for _, shape := range cell.Shapes {
+
+			if nearestVisited.Contains(shape) {
+				continue
+			}
+
+			nearestVisited.Add(shape)
+
+			if !shape.IsActive() || (filter != nil && !filter(shape)) {
+				continue
+			}
+
+			result := NearestResult{Shape: shape}
+
+			if surface {
+				result.Point = closestPointOn(shape, point)
+			} else {
+				result.Point = shapeCenter(shape)
+			}
+
+			result.Distance = point.Distance(result.Point)
+
+			if len(results) < k {
+				heap.Push(&results, result)
+			} else if result.Distance < results[0].Distance {
+				results[0] = result
+				heap.Fix(&results, 0)
+			}
+
+		}
+
+	}
+
+	for ring := 0; float64(ring) <= maxRing; ring++ {
+
+		// Every Shape that hasn't been visited yet is entirely outside of the Cells checked so far, so it can't be any closer than their edges
+		if len(results) == k && ring > 0 {
+			inner := ring - 1
+			bound := min(
+				min(point.X-float64(cx-inner)*cw, float64(cx+inner+1)*cw-point.X),
+				min(point.Y-float64(cy-inner)*ch, float64(cy+inner+1)*ch-point.Y),
+			)
+			if bound >= results[0].Distance {
+				break
+			}
+		}
+
+		if ring == 0 {
+			visit(cx, cy)
+			continue
+		}
+
+		for x := cx - ring; x <= cx+ring; x++ {
+			visit(x, cy-ring)
+			visit(x, cy+ring)
+		}
+
+		for y := cy - ring + 1; y <= cy+ring-1; y++ {
+			visit(cx-ring, y)
+			visit(cx+ring, y)
+		}
+
+	}
+
+	sorted := []NearestResult(results)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Distance < sorted[j].Distance
+	})
+
+	return sorted
+
+}
+
+// closestPointOn returns the closest point on the surface of the given Shape to the given point, or the point itself if it's inside of the Shape.
+func closestPointOn(shape IShape, point Vector) Vector {
+
+	switch s := shape.(type) {
+
+	case *Circle:
+
+		diff := point.Sub(s.position)
+
+		if diff.MagnitudeSquared() <= s.radius*s.radius {
+			return point
+		}
+
+		return s.position.Add(diff.Unit().Scale(s.radius))
+
+	case *ConvexPolygon:
+
+		if containsPoint(s, point) {
+			return point
+		}
+
+		points := s.Transformed()
+
+		if len(points) == 1 {
+			return points[0]
+		}
+
+		closest := shapeCenter(s)
+		closestDist := math.MaxFloat64
+
+		for _, line := range s.Lines() {
+
+			edge := line.End.Sub(line.Start)
+			t := 0.0
+			if length := edge.MagnitudeSquared(); length > 0 {
+				t = clamp(point.Sub(line.Start).Dot(edge)/length, 0, 1)
+			}
+
+			p := line.Start.Add(edge.Scale(t))
+
+			if d := p.DistanceSquared(point); d < closestDist {
+				closest = p
+				closestDist = d
+			}
+
+		}
+
+		return closest
+
+	}
+
+	return shape.Position()
+
+}
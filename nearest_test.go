@@ -0,0 +1,134 @@
+package resolv
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestNearest(t *testing.T) {
+
+	space := NewSpace(320, 320, 16, 16)
+
+	a := NewCircle(40, 40, 4)
+	b := NewCircle(60, 40, 4)
+	c := NewCircle(200, 200, 4)
+	// A long wall, with its center far away from the left end
+	wall := NewRectangleFromTopLeft(0, 100, 300, 10)
+	inactive := NewCircle(41, 41, 4)
+	inactive.SetActive(false)
+
+	space.Add(a, b, c, wall, inactive)
+
+	tests := []struct {
+		name    string
+		point   Vector
+		k       int
+		surface bool
+		filter  func(IShape) bool
+		want    []IShape
+	}{
+		{"closest", Vector{45, 40}, 1, false, nil, []IShape{a}},
+		{"in order", Vector{45, 40}, 3, false, nil, []IShape{a, b, wall}},
+		{"more than there are", Vector{45, 40}, 10, false, nil, []IShape{a, b, wall, c}},
+		{"none", Vector{45, 40}, 0, false, nil, nil},
+		{"filtered", Vector{45, 40}, 2, false, func(s IShape) bool { return s != a }, []IShape{b, wall}},
+		// Just across a Cell boundary from the point, but closer than the Shapes in the point's own Cell
+		{"across a cell boundary", Vector{49, 40}, 1, false, nil, []IShape{a}},
+		{"by surface", Vector{10, 90}, 1, true, nil, []IShape{wall}},
+		{"by center", Vector{10, 90}, 1, false, nil, []IShape{a}},
+		{"inside of a Shape", Vector{250, 105}, 1, true, nil, []IShape{wall}},
+		{"outside of the Space", Vector{-100, -100}, 1, false, nil, []IShape{a}},
+	}
+
+	for _, test := range tests {
+
+		t.Run(test.name, func(t *testing.T) {
+
+			var results []NearestResult
+			if test.surface {
+				results = space.NearestSurface(test.point, test.k, test.filter)
+			} else {
+				results = space.Nearest(test.point, test.k, test.filter)
+			}
+
+			if len(results) != len(test.want) {
+				t.Fatalf("found %d Shapes, want %d", len(results), len(test.want))
+			}
+
+			for i := range results {
+				if results[i].Shape != test.want[i] {
+					t.Errorf("result %d is %v, want %v", i, results[i].Shape, test.want[i])
+				}
+			}
+
+		})
+
+	}
+
+	if results := space.NearestSurface(Vector{250, 105}, 1, nil); len(results) != 1 || results[0].Distance != 0 || !results[0].Point.Equals(Vector{250, 105}) {
+		t.Errorf("NearestSurface() from inside of a Shape returned %v, want a distance of 0 at the point", results)
+	}
+
+}
+
+// TestNearestMatchesBruteForce checks that stopping the search early never misses a closer Shape.
+func TestNearestMatchesBruteForce(t *testing.T) {
+
+	space := NewSpace(640, 480, 16, 16)
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 300; i++ {
+		if i%2 == 0 {
+			space.Add(NewCircle(rng.Float64()*640, rng.Float64()*480, 1+rng.Float64()*20))
+		} else {
+			space.Add(NewRectangle(rng.Float64()*640, rng.Float64()*480, 1+rng.Float64()*80, 1+rng.Float64()*20))
+		}
+	}
+
+	for trial := 0; trial < 200; trial++ {
+
+		point := Vector{rng.Float64()*700 - 30, rng.Float64()*540 - 30}
+
+		// Some points exactly on Cell boundaries
+		if trial%4 == 0 {
+			point.X = math.Round(point.X/16) * 16
+		}
+
+		k := 1 + rng.Intn(8)
+
+		for _, surface := range []bool{false, true} {
+
+			want := []float64{}
+			for _, shape := range space.Shapes() {
+				if surface {
+					want = append(want, closestPointOn(shape, point).Distance(point))
+				} else {
+					want = append(want, shapeCenter(shape).Distance(point))
+				}
+			}
+			sort.Float64s(want)
+
+			var results []NearestResult
+			if surface {
+				results = space.NearestSurface(point, k, nil)
+			} else {
+				results = space.Nearest(point, k, nil)
+			}
+
+			if len(results) != k {
+				t.Fatalf("found %d Shapes, want %d", len(results), k)
+			}
+
+			for i := range results {
+				if results[i].Distance != want[i] {
+					t.Fatalf("point %v, k %d, surface %v: result %d is %v away, want %v", point, k, surface, i, results[i].Distance, want[i])
+				}
+			}
+
+		}
+
+	}
+
+}
@@ -0,0 +1,150 @@
+package resolv
+
+import "math"
+
+// oneWayPass represents a one-way Shape that a Shape is passing through.
+type oneWayPass struct {
+	Shape   IShape
+	Entered bool // Whether the passing Shape has actually started overlapping the one-way Shape yet.
+}
+
+// SetOneWay makes the Shape a one-way Shape (like a jump-through platform) that other Shapes can pass through when moving in the given direction.
+// Shapes moving against the direction collide with the one-way Shape only when approaching from the side the direction points to,
+// and only if they weren't already overlapping it. For example, a platform that can be jumped through from below would have a direction of {0, -1}
+// (up in screen-space). The direction doesn't need to be normalized. Setting the direction to a zero Vector makes the Shape a normal, solid Shape again.
+// IntersectionTest(), ShapeLineTest(), LineTest(), and CharacterControllers take one-way Shapes into account automatically.
+func (s *ShapeBase) SetOneWay(direction Vector) {
+	s.oneWayDirection = direction.Unit()
+}
+
+// IsOneWay returns whether the Shape is a one-way Shape; see SetOneWay().
+func (s *ShapeBase) IsOneWay() bool {
+	return !s.oneWayDirection.IsZero()
+}
+
+// OneWayDirection returns the direction that Shapes can pass through the Shape in if it's a one-way Shape; see SetOneWay().
+// If the Shape isn't a one-way Shape, this returns a zero Vector.
+func (s *ShapeBase) OneWayDirection() Vector {
+	return s.oneWayDirection
+}
+
+// DropThrough makes the Shape drop through any one-way Shapes that it's standing on (or is overlapping), such that it passes through them
+// until it's no longer overlapping them. A one-way Shape is considered to be stood on if it's within a Cell's distance of the Shape in the direction
+// opposite to the one-way Shape's direction. This is useful for dropping down through jump-through platforms, for example.
+// Note that the Shape must be in a Space for this to work.
+func (s *ShapeBase) DropThrough() {
+
+	if s.space == nil {
+		return
+	}
+
+	probe := max(float64(s.space.cellWidth), float64(s.space.cellHeight))
+
+	s.owner.SelectTouchingCells(1).FilterShapes().ForEach(func(other IShape) bool {
+
+		if !other.IsOneWay() || s.IsPassingThrough(other) {
+			return true
+		}
+
+		if s.isOverlappingOffset(other, other.OneWayDirection().Scale(-probe)) {
+			s.passingThrough = append(s.passingThrough, oneWayPass{Shape: other})
+		}
+
+		return true
+
+	})
+
+}
+
+// IsPassingThrough returns whether the Shape is currently passing through the given one-way Shape (either because it entered the one-way
+// Shape from the passable side, or because it's dropping through it; see DropThrough()).
+func (s *ShapeBase) IsPassingThrough(other IShape) bool {
+	for _, p := range s.passingThrough {
+		if p.Shape == other {
+			return true
+		}
+	}
+	return false
+}
+
+// isOverlappingOffset returns whether the Shape would overlap the other Shape when moved from its current position by up to the given offset.
+func (s *ShapeBase) isOverlappingOffset(other IShape, offset Vector) bool {
+
+	if isPenetrating(s.owner.Intersection(other)) {
+		return true
+	}
+
+	_, ok := sweepPair(s.owner, offset, other, 1)
+	return ok
+
+}
+
+// updatePassingThrough updates the list of one-way Shapes the Shape is passing through, removing the ones it has finished passing through.
+func (s *ShapeBase) updatePassingThrough() {
+
+	if len(s.passingThrough) == 0 {
+		return
+	}
+
+	probe := 0.0
+	if s.space != nil {
+		probe = max(float64(s.space.cellWidth), float64(s.space.cellHeight))
+	}
+
+	passing := s.passingThrough[:0]
+
+	for _, p := range s.passingThrough {
+
+		if isPenetrating(s.owner.Intersection(p.Shape)) {
+			p.Entered = true
+		} else if p.Entered || !s.isOverlappingOffset(p.Shape, p.Shape.OneWayDirection().Scale(-probe)) {
+			continue
+		}
+
+		passing = append(passing, p)
+
+	}
+
+	for i := len(passing); i < len(s.passingThrough); i++ {
+		s.passingThrough[i] = oneWayPass{}
+	}
+
+	s.passingThrough = passing
+
+}
+
+// resolveOneWay returns whether the given intersection (between the Shape and a one-way Shape) should count as a collision.
+// If it does count, the IntersectionSet's MTV is altered to push the Shape out in the one-way Shape's direction.
+// If it doesn't, the Shape starts passing through the one-way Shape.
+func (s *ShapeBase) resolveOneWay(other IShape, set *IntersectionSet) bool {
+
+	if s.IsPassingThrough(other) {
+		return false
+	}
+
+	if set.IsSensor {
+		return true
+	}
+
+	dir := other.OneWayDirection()
+
+	proj := s.owner.Project(dir)
+	depth := other.Project(dir).Max - proj.Min
+
+	// If the Shape's more than halfway through the one-way Shape, then it didn't approach from the solid side.
+	if depth > 0 && depth <= (proj.Max-proj.Min)/2 {
+		set.MTV = dir.Scale(depth)
+		return true
+	}
+
+	s.passingThrough = append(s.passingThrough, oneWayPass{Shape: other, Entered: true})
+
+	return false
+
+}
+
+// oneWayLineHit returns whether a line moving in the given direction should hit a one-way Shape at a point on a surface with the given normal.
+func oneWayLineHit(other IShape, lineDir, normal Vector) bool {
+	dir := other.OneWayDirection()
+	return lineDir.Dot(dir) < 0 && normal.Dot(dir) > math.Cos(ToRadians(89))
+}
@@ -0,0 +1,153 @@
+package resolv
+
+import (
+	"math"
+	"testing"
+)
+
+func TestOneWayIntersectionTest(t *testing.T) {
+
+	tests := []struct {
+		name        string
+		start       Vector
+		velocity    Vector
+		dropThrough bool
+		wantY       float64
+	}{
+		{"landing on top", Vector{50, 40}, Vector{0, 4}, false, 90},
+		{"jumping up through", Vector{50, 160}, Vector{0, -4}, false, 80},
+		{"walking in from the side, mostly above it", Vector{-20, 95}, Vector{4, 0}, false, 90},
+		{"walking in from the side, mostly below it", Vector{-20, 108}, Vector{4, 0}, false, 108},
+		{"dropping through", Vector{50, 90}, Vector{0, 4}, true, 170},
+	}
+
+	for _, test := range tests {
+
+		t.Run(test.name, func(t *testing.T) {
+
+			space := NewSpace(640, 480, 16, 16)
+
+			platform := NewRectangleFromTopLeft(0, 100, 100, 10)
+			platform.SetOneWay(Vector{0, -1})
+			space.Add(platform)
+
+			player := NewRectangle(test.start.X, test.start.Y, 20, 20)
+			space.Add(player)
+
+			if test.dropThrough {
+				player.DropThrough()
+				if !player.IsPassingThrough(platform) {
+					t.Fatalf("player isn't dropping through the platform")
+				}
+			}
+
+			// Move the player a step at a time, pushing it out of anything it hits, like a game loop would
+			for i := 0; i < 20; i++ {
+
+				player.MoveVec(test.velocity)
+
+				player.IntersectionTest(IntersectionTestSettings{
+					TestAgainst: space.Shapes(),
+					OnIntersect: func(set IntersectionSet) bool {
+						if set.MTV.Dot(platform.OneWayDirection()) <= 0 {
+							t.Errorf("pushed by %v, against the platform's one-way direction", set.MTV)
+						}
+						player.MoveVec(set.MTV)
+						return true
+					},
+				})
+
+			}
+
+			if y := player.Position().Y; y != test.wantY {
+				t.Errorf("ended up at a Y of %v, want %v", y, test.wantY)
+			}
+
+		})
+
+	}
+
+}
+
+func TestOneWayLineTests(t *testing.T) {
+
+	platform := NewRectangleFromTopLeft(0, 100, 100, 10)
+	platform.SetOneWay(Vector{0, -1})
+
+	tests := []struct {
+		name       string
+		start, end Vector
+		wantHit    bool
+	}{
+		{"from above", Vector{50, 50}, Vector{50, 150}, true},
+		{"from below", Vector{50, 150}, Vector{50, 50}, false},
+		{"from the side", Vector{-50, 105}, Vector{50, 105}, false},
+	}
+
+	for _, test := range tests {
+
+		t.Run(test.name, func(t *testing.T) {
+
+			hit := LineTest(LineTestSettings{
+				Start:       test.start,
+				End:         test.end,
+				TestAgainst: ShapeCollection{platform},
+			})
+
+			if hit != test.wantHit {
+				t.Errorf("LineTest() = %v, want %v", hit, test.wantHit)
+			}
+
+			_, rayHit := raycastShape(test.start, test.end, platform, nil)
+
+			if rayHit != test.wantHit {
+				t.Errorf("ray hit = %v, want %v", rayHit, test.wantHit)
+			}
+
+		})
+
+	}
+
+}
+
+func TestOneWayCharacterController(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		jumpSpeed float64
+		wantY     float64
+	}{
+		{"jumping up onto the platform", 12, 90},
+		{"jumping up into the platform and falling back down", 6, 190},
+	}
+
+	for _, test := range tests {
+
+		t.Run(test.name, func(t *testing.T) {
+
+			space := NewSpace(640, 480, 16, 16)
+
+			platform := NewRectangleFromTopLeft(0, 100, 100, 10)
+			platform.SetOneWay(Vector{0, -1})
+			space.Add(platform, NewRectangleFromTopLeft(0, 200, 640, 20))
+
+			player := NewRectangle(50, 190-0.01, 20, 20)
+			space.Add(player)
+
+			cc := NewCharacterController(player)
+
+			velocity := Vector{0, -test.jumpSpeed}
+
+			for i := 0; i < 120; i++ {
+				velocity = cc.Move(velocity.Add(Vector{0, 0.25}))
+			}
+
+			if !cc.IsOnFloor() || math.Abs(player.Position().Y-test.wantY) > cc.Margin*2 {
+				t.Errorf("IsOnFloor() = %v at a Y of %v, want true at %v", cc.IsOnFloor(), player.Position().Y, test.wantY)
+			}
+
+		})
+
+	}
+
+}
@@ -0,0 +1,300 @@
+package resolv
+
+import (
+	"math"
+	"sort"
+)
+
+// ResolveOverlaps pushes the given Shape out of any of the given Shapes it's overlapping, returning the total correction applied to the Shape
+// and the IntersectionSets of the contacts that were resolved.
+//
+// Applying each IntersectionSet's MTV one at a time (as you might in an IntersectionTest() callback) depends on the order the contacts are handled in,
+// and can push a Shape into its neighbors (for example, catching on the seam between two floor tiles and being pushed sideways). Instead, ResolveOverlaps
+// gathers all of the contacts, and then finds the smallest single correction that separates the Shape from all of them at once (so a box sunk into two
+// floor tiles is pushed straight up, regardless of the order the tiles are given in). Each resolved IntersectionSet's MTV is set to the part of the
+// correction that separates the Shape from that contact (i.e. the floor's surface, rather than the seam's side). This is repeated up to the given number
+// of iterations, or until the Shape isn't overlapping anything. Shapes the Shape can't collide with (see ShapeBase.CanCollideWith()) and sensors are skipped,
+// and one-way Shapes only ever push the Shape out in their one-way direction, as they do in IntersectionTest().
+func ResolveOverlaps(shape IShape, against ShapeIterator, iterations int) (Vector, []IntersectionSet) {
+
+	total := Vector{}
+	resolved := []IntersectionSet{}
+
+	if shape == nil || against == nil {
+		return total, resolved
+	}
+
+	b := shape.base()
+	b.updatePassingThrough()
+
+	contacts := []overlapContact{}
+
+	for i := 0; i < iterations; i++ {
+
+		contacts = contacts[:0]
+
+		against.ForEach(func(other IShape) bool {
+			if set, ok := b.overlapWith(other); ok {
+				if contact, ok := newOverlapContact(shape, set); ok {
+					contacts = append(contacts, contact)
+				}
+			}
+			return true
+		})
+
+		if len(contacts) == 0 {
+			break
+		}
+
+		// Sorting the contacts means the result doesn't depend on the order the iterator gives them in
+		sort.Slice(contacts, func(i, j int) bool {
+			di, dj := contacts[i].Set.MTV.MagnitudeSquared(), contacts[j].Set.MTV.MagnitudeSquared()
+			if di != dj {
+				return di > dj
+			}
+			return contacts[i].Set.OtherShape.ID() < contacts[j].Set.OtherShape.ID()
+		})
+
+		correction := solveOverlaps(contacts)
+
+		for _, contact := range contacts {
+
+			set := contact.Set
+
+			// Report the part of the correction that resolved the contact, rather than its own MTV
+			if option, ok := contact.resolvedBy(correction); ok {
+				set.MTV = option.Axis.Scale(option.Depth)
+			}
+
+			found := false
+			for r := range resolved {
+				if resolved[r].OtherShape == set.OtherShape {
+					resolved[r] = set
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				resolved = append(resolved, set)
+			}
+
+		}
+
+		if correction.IsZero() {
+			break
+		}
+
+		shape.MoveVec(correction)
+		total = total.Add(correction)
+
+	}
+
+	return total, resolved
+
+}
+
+// overlapOption is one way of separating a Shape from another Shape it overlaps: moving it at least Depth along Axis.
+type overlapOption struct {
+	Axis  Vector
+	Depth float64
+}
+
+// overlapContact is a Shape overlapping another Shape, along with the ways it could be separated from it.
+type overlapContact struct {
+	Set     IntersectionSet
+	Options []overlapOption
+}
+
+// newOverlapContact returns the ways the given Shape could be separated from the Shape it overlaps in the given IntersectionSet.
+// As the Shapes are convex, they're separated once they're separated along any of their separating axes; for ConvexPolygons,
+// these are the normals of their edges, and for Circles, the direction from the other Shape to the Circle's center.
+// If the Shapes are already separated along one of the axes, it returns false.
+func newOverlapContact(shape IShape, set IntersectionSet) (overlapContact, bool) {
+
+	contact := overlapContact{Set: set}
+	other := set.OtherShape
+
+	// One-way Shapes only push in their one-way direction
+	if other.IsOneWay() {
+		contact.Options = append(contact.Options, overlapOption{Axis: set.MTV.Unit(), Depth: set.MTV.Magnitude()})
+		return contact, true
+	}
+
+	axes := []Vector{set.MTV}
+
+	for _, s := range []IShape{shape, other} {
+		if polygon, ok := s.(*ConvexPolygon); ok {
+			axes = append(axes, polygon.SATAxes()...)
+		}
+	}
+
+	circle, isCircle := shape.(*Circle)
+	otherCircle, otherIsCircle := other.(*Circle)
+
+	if isCircle && otherIsCircle {
+		axes = append(axes, circle.position.Sub(otherCircle.position))
+	} else if isCircle {
+		axes = append(axes, circle.position.Sub(closestVertex(other.(*ConvexPolygon), circle.position)))
+	} else if otherIsCircle {
+		axes = append(axes, closestVertex(shape.(*ConvexPolygon), otherCircle.position).Sub(otherCircle.position))
+	}
+
+	for _, axis := range axes {
+
+		if axis.IsZero() {
+			continue
+		}
+
+		axis = axis.Unit()
+
+		for _, dir := range []Vector{axis, axis.Invert()} {
+
+			duplicate := false
+			for _, option := range contact.Options {
+				if option.Axis.Dot(dir) > 1-1e-9 {
+					duplicate = true
+					break
+				}
+			}
+
+			if duplicate {
+				continue
+			}
+
+			depth := other.Project(dir).Max - shape.Project(dir).Min
+
+			if depth <= 0 {
+				return contact, false
+			}
+
+			contact.Options = append(contact.Options, overlapOption{Axis: dir, Depth: depth})
+
+		}
+
+	}
+
+	return contact, true
+
+}
+
+// resolvedBy returns the option with the smallest depth that the given correction satisfies, and whether there is one.
+func (contact overlapContact) resolvedBy(correction Vector) (overlapOption, bool) {
+
+	best := overlapOption{Depth: math.MaxFloat64}
+	found := false
+
+	for _, option := range contact.Options {
+		if correction.Dot(option.Axis) >= option.Depth-1e-6 && option.Depth < best.Depth {
+			best = option
+			found = true
+		}
+	}
+
+	return best, found
+
+}
+
+// solveOverlaps returns the smallest correction that separates a Shape from all of the given contacts.
+// A contact is separated by moving far enough along any one of its options, so the smallest correction either moves along
+// a single option (with the other contacts happening to be resolved along the way), or lies where the options of two different
+// contacts are both just satisfied; all of these candidates are tried.
+func solveOverlaps(contacts []overlapContact) Vector {
+
+	best := Vector{}
+	bestDist := math.MaxFloat64
+	found := false
+
+	try := func(correction Vector) {
+
+		dist := correction.Magnitude()
+
+		// Ties are broken by direction rather than by the order the candidates are tried in
+		if dist > bestDist+1e-9 || (dist > bestDist-1e-9 && (correction.Y > best.Y || (correction.Y == best.Y && correction.X >= best.X))) {
+			return
+		}
+
+		for _, contact := range contacts {
+			if _, ok := contact.resolvedBy(correction); !ok {
+				return
+			}
+		}
+
+		best, bestDist, found = correction, dist, true
+
+	}
+
+	for i, contact := range contacts {
+
+		for _, a := range contact.Options {
+
+			try(a.Axis.Scale(a.Depth))
+
+			for _, other := range contacts[i+1:] {
+
+				for _, b := range other.Options {
+
+					det := a.Axis.X*b.Axis.Y - a.Axis.Y*b.Axis.X
+
+					if math.Abs(det) < 1e-9 {
+						continue
+					}
+
+					try(Vector{
+						(a.Depth*b.Axis.Y - a.Axis.Y*b.Depth) / det,
+						(a.Axis.X*b.Depth - a.Depth*b.Axis.X) / det,
+					})
+
+				}
+
+			}
+
+		}
+
+	}
+
+	// This shouldn't happen, as moving far enough in any direction separates convex Shapes, but just in case
+	if !found {
+		return contacts[0].Set.MTV
+	}
+
+	return best
+
+}
+
+// closestVertex returns the vertex of the ConvexPolygon closest to the given point.
+func closestVertex(polygon *ConvexPolygon, point Vector) Vector {
+
+	closest := Vector{}
+	closestDist := math.MaxFloat64
+
+	for _, vertex := range polygon.Transformed() {
+		if dist := vertex.DistanceSquared(point); dist < closestDist {
+			closest, closestDist = vertex, dist
+		}
+	}
+
+	return closest
+
+}
+
+// overlapWith returns the IntersectionSet between the Shape and the other Shape, and whether the Shape is overlapping it (and so needs to be pushed out of it).
+func (s *ShapeBase) overlapWith(other IShape) (IntersectionSet, bool) {
+
+	if !s.owner.CanCollideWith(other) {
+		return IntersectionSet{}, false
+	}
+
+	set := s.owner.Intersection(other)
+
+	if set.IsEmpty() || set.IsSensor {
+		return set, false
+	}
+
+	if other.IsOneWay() && !s.resolveOneWay(other, &set) {
+		return set, false
+	}
+
+	return set, !set.MTV.IsZero()
+
+}
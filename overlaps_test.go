@@ -0,0 +1,83 @@
+package resolv
+
+import (
+	"testing"
+)
+
+func TestResolveOverlaps(t *testing.T) {
+
+	platform := NewRectangleFromTopLeft(0, 100, 100, 10)
+	platform.SetOneWay(Vector{0, -1})
+
+	tests := []struct {
+		name    string
+		shape   func() IShape
+		against ShapeCollection
+		want    Vector
+	}{
+		// A box sunk into the seam between two floor tiles should be pushed straight up, not out to either side
+		{"box on a seam", func() IShape { return NewRectangleFromTopLeft(40, 95, 20, 20) },
+			ShapeCollection{NewRectangleFromTopLeft(0, 100, 50, 20), NewRectangleFromTopLeft(50, 100, 50, 20)}, Vector{0, -15}},
+		{"box mostly on one side of a seam", func() IShape { return NewRectangleFromTopLeft(45, 95, 20, 20) },
+			ShapeCollection{NewRectangleFromTopLeft(0, 100, 50, 20), NewRectangleFromTopLeft(50, 100, 50, 20)}, Vector{0, -15}},
+		{"circle on a seam", func() IShape { return NewCircle(50, 95, 10) },
+			ShapeCollection{NewRectangleFromTopLeft(0, 100, 50, 20), NewRectangleFromTopLeft(50, 100, 50, 20)}, Vector{0, -5}},
+		{"box in a corner", func() IShape { return NewRectangleFromTopLeft(85, 90, 20, 20) },
+			ShapeCollection{NewRectangleFromTopLeft(0, 100, 100, 20), NewRectangleFromTopLeft(100, 0, 20, 120)}, Vector{-5, -10}},
+		{"box on a one-way platform", func() IShape { return NewRectangleFromTopLeft(10, 84, 20, 20) },
+			ShapeCollection{platform}, Vector{0, -4}},
+		{"box under a one-way platform", func() IShape { return NewRectangleFromTopLeft(10, 105, 20, 20) },
+			ShapeCollection{platform}, Vector{}},
+		{"box not overlapping", func() IShape { return NewRectangleFromTopLeft(10, 50, 20, 20) },
+			ShapeCollection{NewRectangleFromTopLeft(0, 100, 100, 20)}, Vector{}},
+	}
+
+	for _, test := range tests {
+
+		t.Run(test.name, func(t *testing.T) {
+
+			// The result shouldn't depend on the order the Shapes are given in
+			reversed := ShapeCollection{}
+			for i := len(test.against) - 1; i >= 0; i-- {
+				reversed = append(reversed, test.against[i])
+			}
+
+			for _, against := range []ShapeCollection{test.against, reversed} {
+
+				shape := test.shape()
+				start := shape.Position()
+
+				correction, contacts := ResolveOverlaps(shape, against, 4)
+
+				if correction.Sub(test.want).Magnitude() > 1e-6 {
+					t.Fatalf("correction is %v, want %v", correction, test.want)
+				}
+
+				if !shape.Position().Equals(start.Add(correction)) {
+					t.Errorf("Shape moved to %v, want %v", shape.Position(), start.Add(correction))
+				}
+
+				if test.want.IsZero() && len(contacts) > 0 {
+					t.Errorf("resolved %d contacts, want none", len(contacts))
+				}
+
+				for _, set := range contacts {
+					// Each contact reports the part of the correction that resolved it
+					if set.MTV.Unit().Dot(correction.Unit()) <= 0 {
+						t.Errorf("contact with %v has an MTV of %v, which doesn't point along the correction %v", set.OtherShape, set.MTV, correction)
+					}
+				}
+
+				for _, other := range against {
+					if set, ok := shape.base().overlapWith(other); ok {
+						t.Errorf("still overlapping %v by %v", other, set.MTV)
+					}
+				}
+
+			}
+
+		})
+
+	}
+
+}
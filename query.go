@@ -0,0 +1,45 @@
+package resolv
+
+// QueryPoint returns a ShapeFilter of the Shapes in the Space that contain the given point.
+// Only the Cells around the point are checked, and the Space isn't modified. Inactive Shapes are skipped, but sensors aren't.
+// Like other ShapeFilters, the query runs each time the ShapeFilter is iterated through, and further filters can be chained onto it.
+func (s *Space) QueryPoint(point Vector) ShapeFilter {
+	return s.FilterCells(Bounds{Min: point, Max: point}).FilterShapes().ByFunc(func(shape IShape) bool {
+		return containsPoint(shape, point)
+	})
+}
+
+// QueryCircle returns a ShapeFilter of the Shapes in the Space that overlap the circle of the given radius around the given center point
+// (for example, everything caught in an explosion). It otherwise works like QueryPoint().
+func (s *Space) QueryCircle(center Vector, radius float64) ShapeFilter {
+	return s.QueryShape(NewCircle(center.X, center.Y, radius))
+}
+
+// QueryBounds returns a ShapeFilter of the Shapes in the Space that overlap the given Bounds (for example, the units inside of a selection box).
+// Unlike FilterCells(), which selects every Shape in the Cells the Bounds touch, only Shapes that actually overlap the Bounds are included.
+// It otherwise works like QueryPoint().
+func (s *Space) QueryBounds(bounds Bounds) ShapeFilter {
+	return s.QueryShape(NewRectangleFromCorners(bounds.Min.X, bounds.Min.Y, bounds.Max.X, bounds.Max.Y))
+}
+
+// QueryShape returns a ShapeFilter of the Shapes in the Space that overlap the given Shape, which doesn't have to be in the Space
+// (for example, a ConvexPolygon drawn out as a lasso selection). If the Shape is in the Space, it's excluded from the results.
+// Shapes are included regardless of whether the given Shape can collide with them, and the Shape isn't tested against its current position until
+// the ShapeFilter is iterated through (though the Cells checked are based on its position when QueryShape() is called). It otherwise works like QueryPoint().
+func (s *Space) QueryShape(shape IShape) ShapeFilter {
+	return s.FilterCells(shape.Bounds()).FilterShapes().ByFunc(func(other IShape) bool {
+		return other != shape && isOverlapping(shape, other)
+	})
+}
+
+// isOverlapping returns whether the given Shapes overlap or touch, including if one is entirely inside of the other
+// (which Intersection() alone doesn't catch, as it relies on the Shapes' edges crossing).
+func isOverlapping(shape, other IShape) bool {
+
+	if !shape.Bounds().IsIntersecting(other.Bounds()) {
+		return false
+	}
+
+	return !shape.Intersection(other).IsEmpty() || containsPoint(other, shapeCenter(shape)) || containsPoint(shape, shapeCenter(other))
+
+}
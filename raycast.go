@@ -0,0 +1,261 @@
+package resolv
+
+import (
+	"math"
+	"sort"
+)
+
+// RaycastHit represents a Shape hit by a ray cast with Space.Raycast() or Space.RaycastAll().
+type RaycastHit struct {
+	Shape    IShape  // The Shape hit.
+	Point    Vector  // The point where the ray hit the Shape.
+	Normal   Vector  // The normal of the surface hit; this always faces back against the ray.
+	Fraction float64 // How far along the ray the hit is, ranging from 0 (the start of the ray) to 1 (the end).
+}
+
+var raycastIDSet = shapeIDSet{}
+
+// Raycast casts a ray from start to end through the Space, returning the first Shape hit and true, or false if nothing was hit.
+// Only the Cells the ray passes through are checked (see Space.FilterCellsInLine()), and the cast stops as soon as the closest hit is known,
+// so long rays across the Space are cheap. filter is an optional function to select which Shapes can be hit; if it's nil, all Shapes can be hit.
+// Sensors and inactive Shapes are never hit, and Shapes the ray starts inside of are ignored. One-way Shapes are only hit from their solid side.
+func (s *Space) Raycast(start, end Vector, filter func(shape IShape) bool) (RaycastHit, bool) {
+
+	best := RaycastHit{Fraction: math.MaxFloat64}
+	found := false
+
+	raycastIDSet = raycastIDSet[:0]
+
+	s.walkCellsInLine(start, end, func(cx, cy int, tExit float64) bool {
+
+		if cell := s.Cell(cx, cy); cell != nil {
+
+			for _, shape := range cell.Shapes {
+
+				if raycastIDSet.idInSet(shape.ID()) {
+					continue
+				}
+
+				raycastIDSet = append(raycastIDSet, shape.ID())
+
+				if hit, ok := raycastShape(start, end, shape, filter); ok && hit.Fraction < best.Fraction {
+					best = hit
+					found = true
+				}
+
+			}
+
+		}
+
+		// Any hits in later Cells would be further along the ray than this
+		return !found || best.Fraction > tExit
+
+	})
+
+	return best, found
+
+}
+
+// RaycastAll casts a ray from start to end through the Space, returning all of the Shapes hit, sorted by their distance along the ray.
+// Each Shape is only hit once (where the ray enters it). filter works as it does for Space.Raycast().
+func (s *Space) RaycastAll(start, end Vector, filter func(shape IShape) bool) []RaycastHit {
+
+	hits := []RaycastHit{}
+
+	raycastIDSet = raycastIDSet[:0]
+
+	s.walkCellsInLine(start, end, func(cx, cy int, tExit float64) bool {
+
+		if cell := s.Cell(cx, cy); cell != nil {
+
+			for _, shape := range cell.Shapes {
+
+				if raycastIDSet.idInSet(shape.ID()) {
+					continue
+				}
+
+				raycastIDSet = append(raycastIDSet, shape.ID())
+
+				if hit, ok := raycastShape(start, end, shape, filter); ok {
+					hits = append(hits, hit)
+				}
+
+			}
+
+		}
+
+		return true
+
+	})
+
+	sort.Slice(hits, func(i, j int) bool {
+		return hits[i].Fraction < hits[j].Fraction
+	})
+
+	return hits
+
+}
+
+// raycastShape returns where the ray from start to end enters the given Shape, and if it does.
+func raycastShape(start, end Vector, shape IShape, filter func(shape IShape) bool) (RaycastHit, bool) {
+
+	if !shape.IsActive() || shape.IsSensor() || (filter != nil && !filter(shape)) {
+		return RaycastHit{}, false
+	}
+
+	hit, ok := rayShapeIntersection(start, end, shape)
+
+	if !ok || (shape.IsOneWay() && !oneWayLineHit(shape, end.Sub(start), hit.Normal)) {
+		return RaycastHit{}, false
+	}
+
+	return hit, true
+
+}
+
+// rayShapeIntersection returns where the ray from start to end enters the given Shape, and if it does. Rays starting inside of the Shape don't hit it.
+func rayShapeIntersection(start, end Vector, shape IShape) (RaycastHit, bool) {
+
+	delta := end.Sub(start)
+
+	switch s := shape.(type) {
+
+	case *Circle:
+
+		f := start.Sub(s.position)
+		a := delta.Dot(delta)
+		b := 2 * f.Dot(delta)
+		c := f.Dot(f) - s.radius*s.radius
+
+		disc := b*b - 4*a*c
+
+		if a == 0 || c < 0 || disc < 0 {
+			return RaycastHit{}, false
+		}
+
+		t := (-b - math.Sqrt(disc)) / (2 * a)
+
+		if t < 0 || t > 1 {
+			return RaycastHit{}, false
+		}
+
+		point := start.Add(delta.Scale(t))
+
+		return RaycastHit{
+			Shape:    shape,
+			Point:    point,
+			Normal:   point.Sub(s.position).Unit(),
+			Fraction: t,
+		}, true
+
+	case *ConvexPolygon:
+
+		if containsPoint(s, start) {
+			return RaycastHit{}, false
+		}
+
+		best := RaycastHit{Fraction: math.MaxFloat64}
+		found := false
+
+		count := len(s.Points)
+		edges := count
+		if !s.Closed || count <= 2 {
+			edges = count - 1
+		}
+
+		// The points are transformed one at a time rather than using Lines(), so that casting rays doesn't allocate
+		for i := 0; i < edges; i++ {
+
+			lineStart, lineEnd := s.transformedPoint(i), s.transformedPoint((i+1)%count)
+			edge := lineEnd.Sub(lineStart)
+			denom := delta.X*edge.Y - delta.Y*edge.X
+
+			if denom == 0 {
+				continue
+			}
+
+			diff := lineStart.Sub(start)
+			t := (diff.X*edge.Y - diff.Y*edge.X) / denom
+			u := (diff.X*delta.Y - diff.Y*delta.X) / denom
+
+			if t < 0 || t > 1 || u < 0 || u > 1 || t >= best.Fraction {
+				continue
+			}
+
+			normal := Vector{edge.Y, -edge.X}.Unit()
+			if normal.Dot(delta) > 0 {
+				normal = normal.Invert()
+			}
+
+			best = RaycastHit{
+				Shape:    shape,
+				Point:    start.Add(delta.Scale(t)),
+				Normal:   normal,
+				Fraction: t,
+			}
+			found = true
+
+		}
+
+		return best, found
+
+	}
+
+	return RaycastHit{}, false
+
+}
+
+var lineOfSightIDSet = shapeIDSet{}
+
+// HasLineOfSight returns whether there's a clear line from a to b, without any Shapes in the Space blocking it. filter is an optional function
+// to select which Shapes block the line (for example, only walls); if it's nil, all Shapes block it. Shapes are treated as they are by Space.Raycast()
+// (so sensors, inactive Shapes, and Shapes that a is inside of don't block the line, and one-way Shapes only block it from their solid side).
+//
+// HasLineOfSight is meant to be called often (for example, by every enemy, every frame), so it walks through the Cells the line passes through
+// in order, stops at the first blocking Shape it finds, and doesn't allocate any memory.
+func (s *Space) HasLineOfSight(a, b Vector, filter func(shape IShape) bool) bool {
+
+	clear := true
+
+	lineBounds := Bounds{
+		Min: Vector{min(a.X, b.X), min(a.Y, b.Y)},
+		Max: Vector{max(a.X, b.X), max(a.Y, b.Y)},
+	}
+
+	lineOfSightIDSet = lineOfSightIDSet[:0]
+
+	s.walkCellsInLine(a, b, func(cx, cy int, tExit float64) bool {
+
+		cell := s.Cell(cx, cy)
+
+		if cell == nil {
+			return true
+		}
+
+		for _, shape := range cell.Shapes {
+
+			if lineOfSightIDSet.idInSet(shape.ID()) {
+				continue
+			}
+
+			lineOfSightIDSet = append(lineOfSightIDSet, shape.ID())
+
+			// A quick check to skip Shapes that are in the same Cells as the line, but nowhere near it
+			if bounds := shape.Bounds(); bounds.Max.X < lineBounds.Min.X || bounds.Min.X > lineBounds.Max.X || bounds.Max.Y < lineBounds.Min.Y || bounds.Min.Y > lineBounds.Max.Y {
+				continue
+			}
+
+			if _, ok := raycastShape(a, b, shape, filter); ok {
+				clear = false
+				return false
+			}
+
+		}
+
+		return true
+
+	})
+
+	return clear
+
+}
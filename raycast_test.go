@@ -0,0 +1,186 @@
+package resolv
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+type cellPoint struct{ X, Y int }
+
+func walkedCells(s *Space, start, end Vector) ([]cellPoint, []float64) {
+	cells := []cellPoint{}
+	exits := []float64{}
+	s.walkCellsInLine(start, end, func(cx, cy int, tExit float64) bool {
+		cells = append(cells, cellPoint{cx, cy})
+		exits = append(exits, tExit)
+		return true
+	})
+	return cells, exits
+}
+
+func TestWalkCellsInLine(t *testing.T) {
+
+	space := NewSpace(160, 160, 16, 16)
+
+	tests := []struct {
+		name       string
+		start, end Vector
+		want       []cellPoint
+		wantExit   float64 // The exit fraction of the last cell walked
+	}{
+		{"single cell", Vector{2, 2}, Vector{10, 12}, []cellPoint{{0, 0}}, 1},
+		{"zero length", Vector{8, 8}, Vector{8, 8}, []cellPoint{{0, 0}}, 1},
+		{"horizontal", Vector{4, 4}, Vector{60, 4}, []cellPoint{{0, 0}, {1, 0}, {2, 0}, {3, 0}}, 1},
+		{"vertical upwards", Vector{4, 60}, Vector{4, 4}, []cellPoint{{0, 3}, {0, 2}, {0, 1}, {0, 0}}, 1},
+		{"diagonal", Vector{4, 4}, Vector{60, 40}, []cellPoint{{0, 0}, {1, 0}, {1, 1}, {2, 1}, {2, 2}, {3, 2}}, 1},
+		{"starting on a boundary", Vector{16, 4}, Vector{40, 4}, []cellPoint{{1, 0}, {2, 0}}, 1},
+		{"starting on a boundary going backwards", Vector{32, 4}, Vector{4, 4}, []cellPoint{{2, 0}, {1, 0}, {0, 0}}, 1},
+		{"ending on a boundary", Vector{4, 4}, Vector{32, 4}, []cellPoint{{0, 0}, {1, 0}, {2, 0}}, 1},
+		{"through a corner", Vector{8, 8}, Vector{24, 24}, []cellPoint{{0, 0}, {0, 1}, {1, 1}}, 1},
+		{"starting outside of the Space", Vector{-20, 4}, Vector{4, 4}, []cellPoint{{0, 0}}, 1},
+		{"ending outside of the Space", Vector{140, 4}, Vector{180, 4}, []cellPoint{{8, 0}, {9, 0}}, 0.5},
+		{"along the far edge of the Space", Vector{140, 160}, Vector{180, 160}, []cellPoint{{8, 9}, {9, 9}}, 0.5},
+		{"crossing the whole Space", Vector{-1e8, 4}, Vector{1e8, 4}, []cellPoint{{0, 0}, {1, 0}, {2, 0}, {3, 0}, {4, 0}, {5, 0}, {6, 0}, {7, 0}, {8, 0}, {9, 0}}, (1e8 + 160) / 2e8},
+		{"missing the Space", Vector{-20, -20}, Vector{200, -4}, nil, 0},
+		{"entirely outside of the Space", Vector{-20, 4}, Vector{-4, 4}, nil, 0},
+	}
+
+	for _, test := range tests {
+
+		t.Run(test.name, func(t *testing.T) {
+
+			cells, exits := walkedCells(space, test.start, test.end)
+
+			if len(cells) != len(test.want) {
+				t.Fatalf("walked %v, want %v", cells, test.want)
+			}
+
+			for i := range cells {
+				if cells[i] != test.want[i] {
+					t.Fatalf("walked %v, want %v", cells, test.want)
+				}
+			}
+
+			if len(exits) > 0 && math.Abs(exits[len(exits)-1]-test.wantExit) > 1e-9 {
+				t.Errorf("last cell's exit fraction is %v, want %v", exits[len(exits)-1], test.wantExit)
+			}
+
+		})
+
+	}
+
+}
+
+// TestWalkCellsInLineCoverage checks that random lines visit every Cell they pass through, in order.
+func TestWalkCellsInLineCoverage(t *testing.T) {
+
+	space := NewSpace(320, 320, 16, 16)
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 500; i++ {
+
+		start := Vector{rng.Float64() * 320, rng.Float64() * 320}
+		end := Vector{rng.Float64() * 320, rng.Float64() * 320}
+
+		// Snap some of the lines onto Cell boundaries
+		if i%4 == 0 {
+			start.X = math.Round(start.X/16) * 16
+			end.Y = math.Round(end.Y/16) * 16
+		}
+
+		cells, exits := walkedCells(space, start, end)
+
+		visited := map[cellPoint]bool{}
+		for j, c := range cells {
+			visited[c] = true
+			if j > 0 && exits[j] < exits[j-1] {
+				t.Fatalf("line %v -> %v: exit fractions aren't in order: %v", start, end, exits)
+			}
+		}
+
+		for j := 0; j <= 1000; j++ {
+			p := start.Add(end.Sub(start).Scale(float64(j) / 1000))
+			c := cellPoint{int(math.Floor(p.X / 16)), int(math.Floor(p.Y / 16))}
+			if space.Cell(c.X, c.Y) != nil && !visited[c] {
+				t.Fatalf("line %v -> %v: didn't visit cell %v containing %v; visited %v", start, end, c, p, cells)
+			}
+		}
+
+	}
+
+}
+
+func TestRaycast(t *testing.T) {
+
+	space := NewSpace(640, 480, 16, 16)
+
+	box := NewRectangleFromTopLeft(100, 90, 20, 20)
+	circle := NewCircle(300, 100, 10)
+	platform := NewRectangleFromTopLeft(400, 0, 10, 480)
+	platform.SetOneWay(Vector{1, 0})
+	sensor := NewRectangleFromTopLeft(50, 0, 10, 480)
+	sensor.SetSensor(true)
+
+	// A large Shape that's in the first Cells the ray passes through, but is hit further along the ray than the box
+	wide := NewConvexPolygon(0, 0, []float64{20, 60, 200, 60, 200, 140})
+
+	space.Add(box, circle, platform, sensor, wide)
+
+	tests := []struct {
+		name       string
+		start, end Vector
+		filter     func(IShape) bool
+		wantShape  IShape
+		wantPoint  Vector
+		wantNormal Vector
+	}{
+		{"box", Vector{10, 100}, Vector{600, 100}, nil, box, Vector{100, 100}, Vector{-1, 0}},
+		{"closest hit in a later cell", Vector{10, 100}, Vector{600, 100}, func(s IShape) bool { return s != box }, wide, Vector{110, 100}, Vector{-0.4061, 0.9138}},
+		{"circle", Vector{250, 100}, Vector{350, 100}, nil, circle, Vector{290, 100}, Vector{-1, 0}},
+		{"circle from above", Vector{300, 40}, Vector{300, 200}, nil, circle, Vector{300, 90}, Vector{0, -1}},
+		{"one-way from the solid side", Vector{600, 100}, Vector{350, 100}, nil, platform, Vector{410, 100}, Vector{1, 0}},
+		{"one-way from the passable side", Vector{350, 100}, Vector{600, 100}, nil, nil, Vector{}, Vector{}},
+		{"starting inside", Vector{110, 100}, Vector{110, 300}, func(s IShape) bool { return s == box }, nil, Vector{}, Vector{}},
+		{"too short", Vector{10, 100}, Vector{90, 100}, nil, nil, Vector{}, Vector{}},
+	}
+
+	for _, test := range tests {
+
+		t.Run(test.name, func(t *testing.T) {
+
+			hit, ok := space.Raycast(test.start, test.end, test.filter)
+
+			if test.wantShape == nil {
+				if ok {
+					t.Fatalf("hit %v at %v, want no hit", hit.Shape, hit.Point)
+				}
+				return
+			}
+
+			if !ok || hit.Shape != test.wantShape {
+				t.Fatalf("hit %v (%v), want %v", hit.Shape, ok, test.wantShape)
+			}
+
+			if hit.Point.Distance(test.wantPoint) > 0.001 {
+				t.Errorf("hit point %v, want %v", hit.Point, test.wantPoint)
+			}
+
+			if hit.Normal.Distance(test.wantNormal) > 0.001 {
+				t.Errorf("hit normal %v, want %v", hit.Normal, test.wantNormal)
+			}
+
+			wantFraction := test.start.Distance(test.wantPoint) / test.start.Distance(test.end)
+			if math.Abs(hit.Fraction-wantFraction) > 0.0001 {
+				t.Errorf("hit fraction %v, want %v", hit.Fraction, wantFraction)
+			}
+
+		})
+
+	}
+
+	if hits := space.RaycastAll(Vector{10, 100}, Vector{600, 100}, nil); len(hits) != 3 || hits[0].Shape != box || hits[1].Shape != wide || hits[2].Shape != circle {
+		t.Errorf("RaycastAll() hit %v, want the box, the wide polygon, and the circle in order", hits)
+	}
+
+}
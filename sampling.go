@@ -0,0 +1,250 @@
+package resolv
+
+import (
+	"math"
+	"math/rand"
+)
+
+// RandomPointIn returns a uniformly distributed random point inside of the given Shape, using the given random number generator
+// (so the results are deterministic for a given seed). If the Shape is a ConvexPolygon with fewer than 3 points, a random point along its lines is returned instead.
+func RandomPointIn(shape IShape, rng *rand.Rand) Vector {
+
+	switch s := shape.(type) {
+
+	case *Circle:
+		// The square root makes the points uniform across the area of the Circle, rather than bunched up in the middle
+		r := s.radius * math.Sqrt(rng.Float64())
+		angle := rng.Float64() * math.Pi * 2
+		return s.position.Add(Vector{math.Cos(angle) * r, math.Sin(angle) * r})
+
+	case *ConvexPolygon:
+
+		points := s.Transformed()
+
+		switch len(points) {
+		case 0:
+			return s.position
+		case 1:
+			return points[0]
+		case 2:
+			return points[0].Add(points[1].Sub(points[0]).Scale(rng.Float64()))
+		}
+
+		// Pick a triangle from a fan around the first point, weighted by area, and then a random point within it
+		total := 0.0
+		for i := 1; i < len(points)-1; i++ {
+			total += triangleArea(points[0], points[i], points[i+1])
+		}
+
+		pick := rng.Float64() * total
+		i := 1
+
+		for ; i < len(points)-2; i++ {
+			area := triangleArea(points[0], points[i], points[i+1])
+			if pick < area {
+				break
+			}
+			pick -= area
+		}
+
+		u, v := rng.Float64(), rng.Float64()
+		if u+v > 1 {
+			u, v = 1-u, 1-v
+		}
+
+		return points[0].Add(points[i].Sub(points[0]).Scale(u)).Add(points[i+1].Sub(points[0]).Scale(v))
+
+	}
+
+	return shape.Position()
+
+}
+
+// SamplePointsIn returns the given number of uniformly distributed random points inside of the given Shape, generated deterministically from the given seed.
+func SamplePointsIn(shape IShape, count int, seed int64) []Vector {
+	rng := rand.New(rand.NewSource(seed))
+	points := make([]Vector, 0, count)
+	for i := 0; i < count; i++ {
+		points = append(points, RandomPointIn(shape, rng))
+	}
+	return points
+}
+
+func triangleArea(a, b, c Vector) float64 {
+	return math.Abs((b.X-a.X)*(c.Y-a.Y)-(b.Y-a.Y)*(c.X-a.X)) / 2
+}
+
+// PoissonDiskSettings is a struct of settings for PoissonDiskSample().
+type PoissonDiskSettings struct {
+	Bounds      Bounds  // The area to sample points in.
+	MinDistance float64 // The minimum distance between any two points.
+	Seed        int64   // The seed for the random number generator; the same settings and seed always produce the same points.
+	// MaxAttempts is how many times to try placing a new point around each existing point before giving up on it. Higher values pack
+	// points more tightly, but take longer. Defaults to 30 if 0 or less.
+	MaxAttempts int
+	MaxPoints   int // The maximum number of points to return. If 0 or less, there's no limit.
+	// Avoid is an optional set of Shapes to avoid; points inside of these Shapes (or within Margin of them) are rejected.
+	// This can be a ShapeFilter (for example, from Space.FilterShapes()) to avoid level geometry.
+	Avoid  ShapeIterator
+	Margin float64 // How far points must be from the Shapes in Avoid.
+}
+
+// PoissonDiskSample returns randomly placed points within the given Bounds that are at least MinDistance apart from each other, but are otherwise
+// packed evenly (as opposed to purely random points, which clump together and leave gaps). This is useful for procedurally placing items, trees, enemies, and so on.
+// Points inside of the Shapes in Avoid are rejected. The points are generated deterministically from the settings' Seed using Bridson's algorithm.
+func PoissonDiskSample(settings PoissonDiskSettings) []Vector {
+
+	bounds := settings.Bounds
+	r := settings.MinDistance
+
+	if r <= 0 || bounds.Width() <= 0 || bounds.Height() <= 0 {
+		return nil
+	}
+
+	attempts := settings.MaxAttempts
+	if attempts <= 0 {
+		attempts = 30
+	}
+
+	rng := rand.New(rand.NewSource(settings.Seed))
+
+	var avoid ShapeCollection
+	if settings.Avoid != nil {
+		settings.Avoid.ForEach(func(shape IShape) bool {
+			avoid = append(avoid, shape)
+			return true
+		})
+	}
+
+	var marginCircle *Circle
+	if settings.Margin > 0 {
+		marginCircle = NewCircle(0, 0, settings.Margin)
+	}
+
+	// Each grid cell can contain at most one point, as its diagonal is MinDistance long
+	cellSize := r / math.Sqrt2
+	gridW := int(math.Ceil(bounds.Width() / cellSize))
+	gridH := int(math.Ceil(bounds.Height() / cellSize))
+	grid := make([]int, gridW*gridH)
+	for i := range grid {
+		grid[i] = -1
+	}
+
+	cellOf := func(p Vector) (int, int) {
+		x := int((p.X - bounds.Min.X) / cellSize)
+		y := int((p.Y - bounds.Min.Y) / cellSize)
+		if x >= gridW {
+			x = gridW - 1
+		}
+		if y >= gridH {
+			y = gridH - 1
+		}
+		return x, y
+	}
+
+	points := []Vector{}
+	active := []int{}
+
+	isValid := func(p Vector) bool {
+
+		if p.X < bounds.Min.X || p.Y < bounds.Min.Y || p.X >= bounds.Max.X || p.Y >= bounds.Max.Y {
+			return false
+		}
+
+		cx, cy := cellOf(p)
+
+		for y := cy - 2; y <= cy+2; y++ {
+			for x := cx - 2; x <= cx+2; x++ {
+				if x < 0 || y < 0 || x >= gridW || y >= gridH {
+					continue
+				}
+				if i := grid[y*gridW+x]; i >= 0 && points[i].DistanceSquared(p) < r*r {
+					return false
+				}
+			}
+		}
+
+		if marginCircle != nil {
+			marginCircle.position = p
+		}
+
+		for _, shape := range avoid {
+
+			if marginCircle != nil {
+				if shape.Bounds().IsIntersecting(marginCircle.Bounds()) && (containsPoint(shape, p) || isPenetrating(marginCircle.Intersection(shape))) {
+					return false
+				}
+			} else if shape.Bounds().IsIntersecting(Bounds{Min: p, Max: p}) && containsPoint(shape, p) {
+				return false
+			}
+
+		}
+
+		return true
+
+	}
+
+	add := func(p Vector) {
+		cx, cy := cellOf(p)
+		grid[cy*gridW+cx] = len(points)
+		active = append(active, len(points))
+		points = append(points, p)
+	}
+
+	full := func() bool {
+		return settings.MaxPoints > 0 && len(points) >= settings.MaxPoints
+	}
+
+	randomPoint := func() Vector {
+		return Vector{bounds.Min.X + rng.Float64()*bounds.Width(), bounds.Min.Y + rng.Float64()*bounds.Height()}
+	}
+
+	for !full() {
+
+		// Seed a new area with a random point; this also lets sampling continue into areas cut off from the others by Shapes in Avoid
+		seeded := false
+		for i := 0; i < attempts; i++ {
+			if p := randomPoint(); isValid(p) {
+				add(p)
+				seeded = true
+				break
+			}
+		}
+
+		if !seeded {
+			break
+		}
+
+		for len(active) > 0 && !full() {
+
+			ai := rng.Intn(len(active))
+			center := points[active[ai]]
+			placed := false
+
+			for i := 0; i < attempts; i++ {
+
+				// Try a random point in the ring between MinDistance and twice that around the center point
+				angle := rng.Float64() * math.Pi * 2
+				dist := r * (1 + rng.Float64())
+				p := center.Add(Vector{math.Cos(angle) * dist, math.Sin(angle) * dist})
+
+				if isValid(p) {
+					add(p)
+					placed = true
+					break
+				}
+
+			}
+
+			if !placed {
+				active[ai] = active[len(active)-1]
+				active = active[:len(active)-1]
+			}
+
+		}
+
+	}
+
+	return points
+
+}
@@ -2,6 +2,7 @@ package resolv
 
 import (
 	"math"
+	"reflect"
 	"sort"
 )
 
@@ -47,6 +48,51 @@ type IShape interface {
 	VecTo(other IShape) Vector
 	DistanceTo(other IShape) float64
 	DistanceSquaredTo(other IShape) float64
+
+	CollisionLayer() Layers
+	SetCollisionLayer(layers Layers)
+	CollisionMask() Layers
+	SetCollisionMask(layers Layers)
+	CollisionGroup() int
+	SetCollisionGroup(group int)
+	CanCollideWith(other IShape) bool
+
+	Ignore(shapes ...IShape)
+	Unignore(shapes ...IShape)
+	ClearIgnored()
+	IgnoredShapes() ShapeCollection
+	SetIgnoreSameData(ignore bool)
+	IgnoresSameData() bool
+	IsIgnoring(other IShape) bool
+
+	IsSensor() bool
+	SetSensor(sensor bool)
+
+	IsActive() bool
+	SetActive(active bool)
+
+	SetOneWay(direction Vector)
+	IsOneWay() bool
+	OneWayDirection() Vector
+	DropThrough()
+	IsPassingThrough(other IShape) bool
+
+	Project(axis Vector) Projection
+
+	Velocity() Vector
+	AngularVelocity() float64
+	VelocityAt(point Vector) Vector
+	SetCarrier(carrier IShape) bool
+	Carrier() IShape
+	Riders() ShapeCollection
+
+	CorrectCorner(set IntersectionSet, settings CornerCorrectionSettings) bool
+	ProbeSurfaces(settings SurfaceProbeSettings) SurfaceProbe
+	CheckFloorAhead(settings LedgeQuerySettings) (FloorAhead, bool)
+	FindLedge(settings LedgeQuerySettings) (Ledge, bool)
+	ShapeCast(settings ShapeCastSettings) (ShapeCastHit, bool)
+
+	base() *ShapeBase
 }
 
 // ShapeBase implements many of the common methods that Shapes need to implement to fulfill IShape
@@ -59,6 +105,25 @@ type ShapeBase struct {
 	data          any    // Data represents some helper data present on the shape.
 	owner         IShape // The owning shape; this allows ShapeBase to call overridden functions (i.e. owner.Bounds()).
 	id            uint32
+
+	collisionLayer Layers // The layers the Shape is on.
+	collisionMask  Layers // The layers the Shape collides with.
+	collisionGroup int    // The collision group of the Shape; see SetCollisionGroup().
+
+	ignoredShapes  ShapeCollection // Shapes that the Shape never collides with.
+	ignoreSameData bool            // Whether the Shape ignores other Shapes that share the same Data.
+
+	sensor   bool // Whether the Shape is a sensor (a trigger that reports intersections, but never pushes anything).
+	inactive bool // Whether the Shape is inactive (and so is excluded from selections and tests).
+
+	oneWayDirection Vector       // The direction other Shapes can pass through the Shape in, if it's a one-way Shape.
+	passingThrough  []oneWayPass // The one-way Shapes the Shape is passing through.
+
+	velocity, lastPosition        Vector
+	angularVelocity, lastRotation float64
+	motionTracked                 bool            // Whether the Shape's last position and rotation have been recorded yet.
+	carrier                       IShape          // The Shape carrying this Shape.
+	riders                        ShapeCollection // The Shapes this Shape carries.
 }
 
 var globalShapeID = uint32(0)
@@ -68,12 +133,18 @@ func newShapeBase(x, y float64) ShapeBase {
 	id := globalShapeID
 	globalShapeID++
 	return ShapeBase{
-		position: NewVector(x, y),
-		tags:     &t,
-		id:       id,
+		position:       NewVector(x, y),
+		tags:           &t,
+		id:             id,
+		collisionLayer: LayerDefault,
+		collisionMask:  LayerAll,
 	}
 }
 
+func (s *ShapeBase) base() *ShapeBase {
+	return s
+}
+
 // ID returns the unique ID of the Shape.
 func (s *ShapeBase) ID() uint32 {
 	return s.id
@@ -94,11 +165,139 @@ func (s *ShapeBase) Tags() *Tags {
 	return s.tags
 }
 
+// CollisionLayer returns the collision layers the Shape is on. By default, a Shape is on LayerDefault.
+func (s *ShapeBase) CollisionLayer() Layers {
+	return s.collisionLayer
+}
+
+// SetCollisionLayer sets the collision layers the Shape is on.
+func (s *ShapeBase) SetCollisionLayer(layers Layers) {
+	s.collisionLayer = layers
+}
+
+// CollisionMask returns the collision layers the Shape collides with. By default, a Shape collides with all layers (LayerAll).
+func (s *ShapeBase) CollisionMask() Layers {
+	return s.collisionMask
+}
+
+// SetCollisionMask sets the collision layers the Shape collides with.
+func (s *ShapeBase) SetCollisionMask(layers Layers) {
+	s.collisionMask = layers
+}
+
+// CollisionGroup returns the collision group of the Shape. By default, a Shape has a collision group of 0 (no group).
+func (s *ShapeBase) CollisionGroup() int {
+	return s.collisionGroup
+}
+
+// SetCollisionGroup sets the collision group of the Shape. Shapes that share the same positive group always collide
+// (regardless of layers and masks), while Shapes that share the same negative group never collide (e.g. the segments of a ragdoll).
+// A group of 0 means the Shape has no group and only its layers and mask are taken into account.
+func (s *ShapeBase) SetCollisionGroup(group int) {
+	s.collisionGroup = group
+}
+
+// Ignore adds the given Shapes to the Shape's ignore list. A Shape never collides with Shapes it ignores, and vice-versa
+// (e.g. a bullet can ignore the Shape of whoever fired it).
+func (s *ShapeBase) Ignore(shapes ...IShape) {
+	for _, shape := range shapes {
+		if shape != nil && !s.IsIgnoring(shape) {
+			s.ignoredShapes = append(s.ignoredShapes, shape)
+		}
+	}
+}
+
+// Unignore removes the given Shapes from the Shape's ignore list.
+func (s *ShapeBase) Unignore(shapes ...IShape) {
+	for _, shape := range shapes {
+		for i, ignored := range s.ignoredShapes {
+			if ignored == shape {
+				s.ignoredShapes[i] = nil
+				s.ignoredShapes = append(s.ignoredShapes[:i], s.ignoredShapes[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// ClearIgnored clears the Shape's ignore list.
+func (s *ShapeBase) ClearIgnored() {
+	for i := range s.ignoredShapes {
+		s.ignoredShapes[i] = nil
+	}
+	s.ignoredShapes = s.ignoredShapes[:0]
+}
+
+// IgnoredShapes returns a new ShapeCollection consisting of the Shapes in the Shape's ignore list.
+func (s *ShapeBase) IgnoredShapes() ShapeCollection {
+	return append(make(ShapeCollection, 0, len(s.ignoredShapes)), s.ignoredShapes...)
+}
+
+// SetIgnoreSameData sets whether the Shape ignores other Shapes that share the same (non-nil) Data.
+// This is useful for game objects composed of multiple Shapes, or for projectiles that share their shooter's Data.
+func (s *ShapeBase) SetIgnoreSameData(ignore bool) {
+	s.ignoreSameData = ignore
+}
+
+// IgnoresSameData returns whether the Shape ignores other Shapes that share the same (non-nil) Data.
+func (s *ShapeBase) IgnoresSameData() bool {
+	return s.ignoreSameData
+}
+
+// IsIgnoring returns whether the Shape ignores the other Shape, either because the other Shape is in the Shape's ignore list,
+// or because the Shape ignores other Shapes that share the same Data.
+func (s *ShapeBase) IsIgnoring(other IShape) bool {
+
+	for _, ignored := range s.ignoredShapes {
+		if ignored == other {
+			return true
+		}
+	}
+
+	if s.ignoreSameData && s.data != nil {
+		otherData := other.Data()
+		// Checking the values rather than their types, as a comparable type (like a struct with an interface field) can still hold uncomparable values
+		if otherData != nil && reflect.ValueOf(s.data).Comparable() && reflect.ValueOf(otherData).Comparable() && s.data == otherData {
+			return true
+		}
+	}
+
+	return false
+
+}
+
+// IsSensor returns whether the Shape is a sensor; see SetSensor().
+func (s *ShapeBase) IsSensor() bool {
+	return s.sensor
+}
+
+// SetSensor sets whether the Shape is a sensor. A sensor (or trigger) is a Shape that reports intersections, but never pushes anything;
+// this is useful for zones like checkpoints, water, or kill planes.
+// Intersections involving a sensor are flagged as such (IntersectionSet.IsSensor) and have a zero MTV.
+// IntersectionTest() still reports sensors (including Shapes entirely inside of them), but ShapeLineTest() ignores them by default.
+func (s *ShapeBase) SetSensor(sensor bool) {
+	s.sensor = sensor
+}
+
+// IsActive returns whether the Shape is active; see SetActive(). Shapes are active by default.
+func (s *ShapeBase) IsActive() bool {
+	return !s.inactive
+}
+
+// SetActive sets whether the Shape is active. An inactive Shape stays in its Space (and in the Space's Cells), but is excluded
+// from CellSelections, Space.FilterShapes(), and all intersection and line tests until it's activated again.
+// This is cheaper than removing a Shape from a Space and adding it back (e.g. for blinking platforms or invincibility frames).
+func (s *ShapeBase) SetActive(active bool) {
+	s.inactive = !active
+}
+
 // Move translates the Shape by the designated X and Y values.
+// Any Shapes it carries (see SetCarrier()) are moved along with it.
 func (s *ShapeBase) Move(x, y float64) {
 	s.position.X += x
 	s.position.Y += y
 	s.update()
+	s.carryRiders(Vector{x, y}, 0)
 }
 
 // MoveVec translates the ShapeBase by the designated Vector.
@@ -112,10 +311,13 @@ func (s *ShapeBase) Position() Vector {
 }
 
 // SetPosition sets the center position of the ShapeBase using the X and Y values given.
+// Any Shapes it carries (see SetCarrier()) are moved along with it.
 func (s *ShapeBase) SetPosition(x, y float64) {
+	delta := Vector{x - s.position.X, y - s.position.Y}
 	s.position.X = x
 	s.position.Y = y
 	s.update()
+	s.carryRiders(delta, 0)
 }
 
 // SetPosition sets the center position of the ShapeBase using the Vector given.
@@ -215,7 +417,8 @@ func (s *ShapeBase) addToTouchingCells() {
 
 // SelectTouchingCells returns a CellSelection of the cells in the Space that the Shape is touching.
 // margin sets the cellular margin - the higher the margin, the further away candidate Shapes can be to be considered for
-// collision. A margin of 1 is a good default. To help visualize which cells contain Shapes, it would be good to implement some kind of debug
+// collision. A margin of 1 is a good default. Shapes that the Shape can't collide with (see ShapeBase.CanCollideWith()) are excluded
+// from the selection. To help visualize which cells contain Shapes, it would be good to implement some kind of debug
 // drawing in your game, like can be seen in resolv's examples.
 func (s *ShapeBase) SelectTouchingCells(margin int) CellSelection {
 
@@ -227,12 +430,12 @@ func (s *ShapeBase) SelectTouchingCells(margin int) CellSelection {
 	ey += margin
 
 	return CellSelection{
-		StartX:      cx,
-		StartY:      cy,
-		EndX:        ex,
-		EndY:        ey,
-		space:       s.space,
-		excludeSelf: s.owner,
+		StartX:   cx,
+		StartY:   cy,
+		EndX:     ex,
+		EndY:     ey,
+		space:    s.space,
+		selector: s.owner,
 	}
 }
 
@@ -269,14 +472,19 @@ var possibleIntersections []possibleIntersection
 // Internally, the function checks to see what Shapes are nearby, and tests against them in order
 // of distance. If the testing Shape moves, then that will influence the result of testing future
 // Shapes in the current game frame.
+// Shapes that the testing Shape can't collide with (see ShapeBase.CanCollideWith()) are skipped.
+// One-way Shapes (see ShapeBase.SetOneWay()) are only intersected if the testing Shape approached them from their solid side;
+// in that case, the MTV pushes the testing Shape out in the one-way Shape's direction.
 // If the test succeeds in finding at least one intersection, it returns true.
 func (s *ShapeBase) IntersectionTest(settings IntersectionTestSettings) bool {
 
 	possibleIntersections = possibleIntersections[:0]
 
+	s.updatePassingThrough()
+
 	settings.TestAgainst.ForEach(func(other IShape) bool {
 
-		if other == s.owner {
+		if !s.owner.CanCollideWith(other) {
 			return true
 		}
 
@@ -298,6 +506,10 @@ func (s *ShapeBase) IntersectionTest(settings IntersectionTestSettings) bool {
 
 		result := s.owner.Intersection(p.Shape)
 
+		if !result.IsEmpty() && p.Shape.IsOneWay() && !s.resolveOneWay(p.Shape, &result) {
+			continue
+		}
+
 		if !result.IsEmpty() {
 			collided = true
 			if settings.OnIntersect != nil {
@@ -315,6 +527,28 @@ func (s *ShapeBase) IntersectionTest(settings IntersectionTestSettings) bool {
 
 }
 
+// applySensor flags the given IntersectionSet as a sensor contact and zeroes out its MTV if either of the Shapes involved is a sensor.
+// As sensors are usually zones that Shapes move around inside of, a Shape entirely inside of a sensor (or a sensor entirely inside of a Shape) is also reported.
+func applySensor(set IntersectionSet, shape, other IShape) IntersectionSet {
+
+	if !shape.IsSensor() && !other.IsSensor() {
+		return set
+	}
+
+	if set.IsEmpty() {
+		contained, ok := containmentSet(shape, other)
+		if !ok {
+			return set
+		}
+		set = contained
+	}
+
+	set.IsSensor = true
+	set.MTV = Vector{}
+	return set
+
+}
+
 func circleConvexTest(circle *Circle, convex *ConvexPolygon) IntersectionSet {
 
 	intersectionSet := IntersectionSet{}
@@ -0,0 +1,346 @@
+package resolv
+
+import "math"
+
+// ShapeCastSettings is a struct of settings for ShapeBase.ShapeCast().
+type ShapeCastSettings struct {
+	Motion Vector // The direction and distance to cast the Shape in.
+	// TestAgainst is the set of Shapes to cast against. If TestAgainst is nil, the Shapes in the Cells the cast passes through are used.
+	TestAgainst    ShapeIterator
+	IncludeSensors bool // Whether to test against sensor Shapes (see ShapeBase.SetSensor()); by default, sensors are ignored.
+}
+
+// ShapeCastHit describes the first Shape hit when casting a Shape along a motion vector.
+type ShapeCastHit struct {
+	Shape    IShape  // The Shape hit.
+	Fraction float64 // How far along the motion vector the cast Shape can move before hitting the other Shape, ranging from 0 to 1.
+	Normal   Vector  // The normal of the surface hit; this points away from the Shape hit, towards the cast Shape.
+}
+
+// ShapeCast casts (sweeps) the Shape along the settings' Motion vector, returning the first Shape it would hit and true, or false if it wouldn't hit anything.
+// Unlike ConvexPolygon.ShapeLineTest(), which casts lines from the ConvexPolygon's vertices, ShapeCast tests the Shape's entire area, so thin
+// obstacles can't slip through between lines, and it works for Circles as well. The time of impact is calculated exactly (using swept SAT for ConvexPolygons,
+// and analytically for Circles), rather than by stepping the Shape along the motion vector.
+//
+// Shapes the Shape can't collide with (see ShapeBase.CanCollideWith()) are skipped. Shapes the Shape is already overlapping at the start are only hit
+// (with a Fraction of 0) if the motion goes further into them. One-way Shapes are only hit if the Shape is moving against their direction and isn't already
+// overlapping or passing through them. The Shape itself isn't moved.
+func (s *ShapeBase) ShapeCast(settings ShapeCastSettings) (ShapeCastHit, bool) {
+
+	testAgainst := settings.TestAgainst
+
+	if testAgainst == nil {
+		if s.space == nil {
+			return ShapeCastHit{}, false
+		}
+		testAgainst = s.space.FilterCells(sweptBounds(s.owner.Bounds(), settings.Motion)).FilterShapes()
+	}
+
+	if !settings.IncludeSensors {
+		testAgainst = ShapeFilter{
+			operatingOn: testAgainst,
+		}.ByFunc(func(shape IShape) bool { return !shape.IsSensor() })
+	}
+
+	return sweepShape(s.owner, settings.Motion, testAgainst)
+
+}
+
+// sweptBounds returns the given Bounds, expanded to cover the area they pass through when moved by the motion vector.
+func sweptBounds(bounds Bounds, motion Vector) Bounds {
+	swept := bounds
+	swept.Min.X = min(bounds.Min.X, bounds.Min.X+motion.X)
+	swept.Min.Y = min(bounds.Min.Y, bounds.Min.Y+motion.Y)
+	swept.Max.X = max(bounds.Max.X, bounds.Max.X+motion.X)
+	swept.Max.Y = max(bounds.Max.Y, bounds.Max.Y+motion.Y)
+	return swept
+}
+
+// sweepShape sweeps the given Shape along the motion vector against the given Shapes, returning the first hit and if there was one.
+// Shapes that are already overlapping the swept Shape at the start are only hit if the motion goes further into them.
+// One-way Shapes are only hit if the swept Shape is moving against their direction and isn't already overlapping or passing through them.
+func sweepShape(shape IShape, motion Vector, against ShapeIterator) (ShapeCastHit, bool) {
+
+	bounds := sweptBounds(shape.Bounds(), motion)
+
+	best := ShapeCastHit{Fraction: math.MaxFloat64}
+	found := false
+
+	against.ForEach(func(other IShape) bool {
+
+		if other == shape || !shape.CanCollideWith(other) || !bounds.IsIntersecting(other.Bounds()) {
+			return true
+		}
+
+		if other.IsOneWay() {
+
+			if shape.IsPassingThrough(other) || motion.Dot(other.OneWayDirection()) >= 0 || isPenetrating(shape.Intersection(other)) {
+				return true
+			}
+
+			if hit, ok := sweepPair(shape, motion, other, best.Fraction); ok {
+				hit.Normal = other.OneWayDirection()
+				best = hit
+				found = true
+			}
+
+			return true
+
+		}
+
+		if set := shape.Intersection(other); isPenetrating(set) {
+			if set.MTV.Dot(motion) < 0 {
+				best = ShapeCastHit{Shape: other, Fraction: 0, Normal: set.MTV.Unit()}
+				found = true
+			}
+			return true
+		}
+
+		if hit, ok := sweepPair(shape, motion, other, best.Fraction); ok {
+			best = hit
+			found = true
+		}
+
+		return true
+
+	})
+
+	return best, found
+
+}
+
+// sweepPair sweeps the given Shape along the motion vector against the other Shape, returning the hit if the Shapes
+// start touching before the given maximum fraction of the motion. The Shapes shouldn't be overlapping at the start.
+func sweepPair(shape IShape, motion Vector, other IShape, maxFraction float64) (ShapeCastHit, bool) {
+
+	var t float64
+	var normal Vector
+	var ok bool
+
+	switch a := shape.(type) {
+
+	case *Circle:
+
+		switch b := other.(type) {
+		case *Circle:
+			t, normal, ok = sweepCircleCircle(a.position, a.radius+b.radius, motion, b.position)
+		case *ConvexPolygon:
+			t, normal, ok = sweepCirclePolygon(a.position, a.radius, motion, b)
+		}
+
+	case *ConvexPolygon:
+
+		switch b := other.(type) {
+		case *Circle:
+			// Sweeping the polygon into the Circle is the same as sweeping the Circle into the polygon the other way
+			t, normal, ok = sweepCirclePolygon(b.position, b.radius, motion.Invert(), a)
+			normal = normal.Invert()
+		case *ConvexPolygon:
+			t, normal, ok = sweepPolygonPolygon(a, motion, b)
+		}
+
+	}
+
+	if !ok || t >= maxFraction {
+		return ShapeCastHit{}, false
+	}
+
+	return ShapeCastHit{Shape: other, Fraction: t, Normal: normal}, true
+
+}
+
+// sweepTolerance is how far (in pixels) Shapes can already be overlapping at the start of a sweep and still be treated as touching.
+const sweepTolerance = 0.0001
+
+// sweepCircleCircle returns when a point moving from start along the motion vector hits a circle of the given radius around center, the normal
+// of the circle at that point, and if it hits.
+func sweepCircleCircle(start Vector, radius float64, motion Vector, center Vector) (float64, Vector, bool) {
+
+	f := start.Sub(center)
+	a := motion.Dot(motion)
+	b := 2 * f.Dot(motion)
+	c := f.Dot(f) - radius*radius
+
+	if a == 0 || b >= 0 {
+		return 0, Vector{}, false
+	}
+
+	// Already touching (or just about), and moving inwards
+	if c <= 0 {
+		if f.Magnitude() < radius-sweepTolerance || f.Unit().Dot(motion.Unit()) > -sweepTolerance {
+			return 0, Vector{}, false
+		}
+		return 0, f.Unit(), true
+	}
+
+	disc := b*b - 4*a*c
+
+	if disc < 0 {
+		return 0, Vector{}, false
+	}
+
+	t := (-b - math.Sqrt(disc)) / (2 * a)
+
+	if t < 0 || t > 1 {
+		return 0, Vector{}, false
+	}
+
+	normal := start.Add(motion.Scale(t)).Sub(center).Unit()
+
+	// Just grazing the circle, like when sliding past the corner where two lines meet
+	if normal.Dot(motion.Unit()) > -sweepTolerance {
+		return 0, Vector{}, false
+	}
+
+	return t, normal, true
+
+}
+
+// sweepCirclePolygon returns when a circle moving from start along the motion vector first touches the polygon, the normal of the polygon there, and if it does.
+// This casts the circle's center against the polygon expanded by the circle's radius (i.e. its edges pushed out by the radius, with rounded corners).
+func sweepCirclePolygon(start Vector, radius float64, motion Vector, polygon *ConvexPolygon) (float64, Vector, bool) {
+
+	bestT := math.MaxFloat64
+	bestNormal := Vector{}
+
+	for _, line := range polygon.Lines() {
+
+		// Use whichever side of the line faces the circle's motion, which also handles lines that aren't part of closed polygons
+		normal := line.Normal()
+		speed := motion.Dot(normal)
+		if speed > 0 {
+			normal = normal.Invert()
+			speed = -speed
+		}
+
+		if speed == 0 {
+			continue
+		}
+
+		dist := start.Sub(line.Start).Dot(normal) - radius
+
+		if dist < -sweepTolerance {
+			continue
+		}
+
+		t := max(-dist/speed, 0)
+
+		if t > 1 || t >= bestT {
+			continue
+		}
+
+		// The circle has to touch the line within its ends; otherwise, it hits a corner (if anything)
+		dir := line.Vector()
+		along := start.Add(motion.Scale(t)).Sub(line.Start).Dot(dir)
+		if along < 0 || along > line.End.Sub(line.Start).Magnitude() {
+			continue
+		}
+
+		bestT = t
+		bestNormal = normal
+
+	}
+
+	for _, vertex := range polygon.Transformed() {
+		if t, normal, ok := sweepCircleCircle(start, radius, motion, vertex); ok && t < bestT {
+			bestT = t
+			bestNormal = normal
+		}
+	}
+
+	if bestT > 1 {
+		return 0, Vector{}, false
+	}
+
+	return bestT, bestNormal, true
+
+}
+
+// sweepPolygonPolygon returns when polygon a, moving along the motion vector, first touches polygon b, the normal of b there, and if it does.
+// This uses swept SAT: the polygons touch during the motion only if, on every separating axis, the time their projections start overlapping
+// is before the time any of them stop overlapping.
+func sweepPolygonPolygon(a *ConvexPolygon, motion Vector, b *ConvexPolygon) (float64, Vector, bool) {
+
+	tEnter := math.Inf(-1)
+	tExit := math.Inf(1)
+	normal := Vector{}
+
+	test := func(axis Vector) bool {
+
+		pa := a.Project(axis)
+		pb := b.Project(axis)
+		speed := motion.Dot(axis)
+
+		var enter, exit float64
+		var n Vector
+
+		switch {
+
+		case pa.Max <= pb.Min+sweepTolerance:
+			// a is before b along the axis
+			if speed <= 0 {
+				return false
+			}
+			enter = (pb.Min - pa.Max) / speed
+			exit = (pb.Max - pa.Min) / speed
+			n = axis.Invert()
+
+		case pb.Max <= pa.Min+sweepTolerance:
+			// a is after b along the axis
+			if speed >= 0 {
+				return false
+			}
+			enter = (pb.Max - pa.Min) / speed
+			exit = (pb.Min - pa.Max) / speed
+			n = axis
+
+		default:
+			// Already overlapping along the axis
+			enter = math.Inf(-1)
+			exit = math.Inf(1)
+			if speed > 0 {
+				exit = (pb.Max - pa.Min) / speed
+			} else if speed < 0 {
+				exit = (pb.Min - pa.Max) / speed
+			}
+
+		}
+
+		if enter > tEnter {
+			tEnter = enter
+			normal = n
+		}
+
+		tExit = min(tExit, exit)
+
+		return tEnter <= tExit && tEnter <= 1
+
+	}
+
+	found := false
+
+	for _, polygon := range []*ConvexPolygon{a, b} {
+
+		lines := polygon.Lines()
+
+		for _, line := range lines {
+			if !test(line.Normal()) {
+				return 0, Vector{}, false
+			}
+			found = true
+		}
+
+		// A single line has no other edges to provide an axis along its length
+		if len(lines) == 1 && !test(lines[0].Vector()) {
+			return 0, Vector{}, false
+		}
+
+	}
+
+	if !found || math.IsInf(tEnter, -1) {
+		return 0, Vector{}, false
+	}
+
+	return max(tEnter, 0), normal, true
+
+}
@@ -0,0 +1,194 @@
+package resolv
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestShapeCast(t *testing.T) {
+
+	floor := NewRectangleFromTopLeft(0, 100, 100, 20)
+	floor2 := NewRectangleFromTopLeft(100, 100, 100, 20)
+	wall := NewRectangleFromTopLeft(200, 0, 2, 100)
+	ball := NewCircle(300, 50, 10)
+	platform := NewRectangleFromTopLeft(0, 200, 100, 10)
+	platform.SetOneWay(Vector{0, -1})
+
+	space := NewSpace(640, 480, 16, 16)
+	space.Add(floor, floor2, wall, ball, platform)
+
+	tests := []struct {
+		name         string
+		shape        IShape
+		motion       Vector
+		against      ShapeCollection
+		wantShape    IShape
+		wantFraction float64
+		wantNormal   Vector
+	}{
+		{"box into wall", NewRectangleFromTopLeft(100, 40, 20, 20), Vector{200, 0}, ShapeCollection{wall}, wall, 0.4, Vector{-1, 0}},
+		{"box into thin wall", NewRectangleFromTopLeft(100, 40, 20, 20), Vector{1000, 0}, ShapeCollection{wall}, wall, 0.08, Vector{-1, 0}},
+		{"box missing", NewRectangleFromTopLeft(100, 40, 20, 20), Vector{0, -100}, ShapeCollection{wall, floor}, nil, 0, Vector{}},
+		{"box falling", NewRectangleFromTopLeft(10, 40, 20, 20), Vector{0, 100}, ShapeCollection{floor}, floor, 0.4, Vector{0, -1}},
+		{"box touching and moving in", NewRectangleFromTopLeft(10, 80, 20, 20), Vector{0, 10}, ShapeCollection{floor}, floor, 0, Vector{0, -1}},
+		{"box touching and moving away", NewRectangleFromTopLeft(10, 80, 20, 20), Vector{0, -10}, ShapeCollection{floor}, nil, 0, Vector{}},
+		{"box sliding along the floor", NewRectangleFromTopLeft(10, 80, 20, 20), Vector{60, 0}, ShapeCollection{floor}, nil, 0, Vector{}},
+		{"box sliding over a seam", NewRectangleFromTopLeft(60, 80, 20, 20), Vector{60, 0}, ShapeCollection{floor, floor2}, nil, 0, Vector{}},
+		{"box overlapping within tolerance", NewRectangleFromTopLeft(10, 80+sweepTolerance/2, 20, 20), Vector{0, 10}, ShapeCollection{floor}, floor, 0, Vector{0, -1}},
+		{"box into circle", NewRectangleFromTopLeft(290, 0, 20, 20), Vector{0, 50}, ShapeCollection{ball}, ball, 0.4, Vector{0, -1}},
+		{"circle into circle", NewCircle(300, 0, 10), Vector{0, 50}, ShapeCollection{ball}, ball, 0.6, Vector{0, -1}},
+		{"circle grazing circle", NewCircle(280, 0, 10), Vector{0, 100}, ShapeCollection{ball}, nil, 0, Vector{}},
+		{"circle into wall", NewCircle(150, 50, 10), Vector{100, 0}, ShapeCollection{wall}, wall, 0.4, Vector{-1, 0}},
+		{"circle into corner", NewCircle(190, -10, 5), Vector{20, 20}, ShapeCollection{wall}, wall, 0.5 - 5/math.Sqrt2/20, Vector{-1, -1}.Unit()},
+		{"circle rolling over a seam", NewCircle(80, 90, 10), Vector{60, 0}, ShapeCollection{floor, floor2}, nil, 0, Vector{}},
+		{"circle touching and moving in", NewCircle(50, 90, 10), Vector{0, 10}, ShapeCollection{floor}, floor, 0, Vector{0, -1}},
+		{"box onto one-way platform", NewRectangleFromTopLeft(10, 150, 20, 20), Vector{0, 100}, ShapeCollection{platform}, platform, 0.3, Vector{0, -1}},
+		{"box up through one-way platform", NewRectangleFromTopLeft(10, 250, 20, 20), Vector{0, -100}, ShapeCollection{platform}, nil, 0, Vector{}},
+		{"box overlapping and moving deeper", NewRectangleFromTopLeft(10, 90, 20, 20), Vector{0, 10}, ShapeCollection{floor}, floor, 0, Vector{0, -1}},
+		{"box overlapping and moving out", NewRectangleFromTopLeft(10, 90, 20, 20), Vector{0, -10}, ShapeCollection{floor}, nil, 0, Vector{}},
+	}
+
+	for _, test := range tests {
+
+		t.Run(test.name, func(t *testing.T) {
+
+			hit, ok := test.shape.ShapeCast(ShapeCastSettings{Motion: test.motion, TestAgainst: test.against})
+
+			if test.wantShape == nil {
+				if ok {
+					t.Fatalf("hit %v at %v, want no hit", hit.Shape, hit.Fraction)
+				}
+				return
+			}
+
+			if !ok || hit.Shape != test.wantShape {
+				t.Fatalf("hit %v (%v), want %v", hit.Shape, ok, test.wantShape)
+			}
+
+			if math.Abs(hit.Fraction-test.wantFraction) > 0.0001 {
+				t.Errorf("fraction %v, want %v", hit.Fraction, test.wantFraction)
+			}
+
+			if hit.Normal.Distance(test.wantNormal) > 0.001 {
+				t.Errorf("normal %v, want %v", hit.Normal, test.wantNormal)
+			}
+
+		})
+
+	}
+
+	// Without TestAgainst, the Cells the cast passes through are used
+	box := NewRectangleFromTopLeft(100, 40, 20, 20)
+	space.Add(box)
+	if hit, ok := box.ShapeCast(ShapeCastSettings{Motion: Vector{300, 0}}); !ok || hit.Shape != wall {
+		t.Errorf("cast through the Space hit %v (%v), want the wall", hit.Shape, ok)
+	}
+
+}
+
+// TestShapeCastTimeOfImpact checks that random casts stop where the Shapes start overlapping.
+func TestShapeCastTimeOfImpact(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(1))
+
+	randomShape := func(x, y float64) IShape {
+		if rng.Intn(2) == 0 {
+			return NewCircle(x, y, 4+rng.Float64()*20)
+		}
+		poly := NewRectangle(x, y, 4+rng.Float64()*40, 4+rng.Float64()*40)
+		poly.SetRotation(rng.Float64() * math.Pi)
+		return poly
+	}
+
+	hits := 0
+
+	for i := 0; i < 1000; i++ {
+
+		shape := randomShape(0, 0)
+		other := randomShape(rng.Float64()*200-100, rng.Float64()*200-100)
+
+		motion := other.Position().Sub(shape.Position()).Add(Vector{rng.Float64()*60 - 30, rng.Float64()*60 - 30}).Scale(1.5)
+
+		overlapsAt := func(fraction float64) bool {
+			shape.MoveVec(motion.Scale(fraction))
+			defer shape.MoveVec(motion.Scale(-fraction))
+			return shapesOverlap(shape, other)
+		}
+
+		// Casts are only exact for Shapes that start apart
+		if overlapsAt(0) {
+			continue
+		}
+
+		hit, ok := shape.ShapeCast(ShapeCastSettings{Motion: motion, TestAgainst: ShapeCollection{other}})
+
+		// A step of half a pixel along the motion
+		step := 0.5 / motion.Magnitude()
+
+		if !ok {
+			for f := 0.0; f <= 1; f += step {
+				if overlapsAt(f) {
+					t.Fatalf("cast %d missed, but the Shapes overlap at fraction %v", i, f)
+				}
+			}
+			continue
+		}
+
+		hits++
+
+		if hit.Fraction > step && overlapsAt(hit.Fraction-step) {
+			t.Fatalf("cast %d hit at fraction %v, but the Shapes already overlap before then", i, hit.Fraction)
+		}
+
+		// Glancing hits can overlap for less than a step, so check finely just past the hit
+		overlapsAfter := false
+		for f := hit.Fraction + step/20; f <= math.Min(hit.Fraction+step, 1); f += step / 20 {
+			if overlapsAt(f) {
+				overlapsAfter = true
+				break
+			}
+		}
+
+		if hit.Fraction+step <= 1 && !overlapsAfter {
+			t.Fatalf("cast %d hit at fraction %v, but the Shapes don't overlap after it", i, hit.Fraction)
+		}
+
+		if hit.Normal.Dot(motion) >= 0 {
+			t.Fatalf("cast %d's normal %v doesn't face against the motion %v", i, hit.Normal, motion)
+		}
+
+	}
+
+	if hits < 100 {
+		t.Errorf("only %d of the casts hit; the test isn't testing much", hits)
+	}
+
+}
+
+// shapesOverlap returns whether the given Shapes overlap, measured exactly (unlike Intersection(), which relies on their edges crossing).
+func shapesOverlap(a, b IShape) bool {
+
+	if circle, ok := a.(*Circle); ok {
+		if other, ok := b.(*Circle); ok {
+			return circle.position.Distance(other.position) < circle.radius+other.radius
+		}
+		return closestPointOn(b, circle.position).Distance(circle.position) < circle.radius
+	}
+
+	if _, ok := b.(*Circle); ok {
+		return shapesOverlap(b, a)
+	}
+
+	pa, pb := a.(*ConvexPolygon), b.(*ConvexPolygon)
+
+	for _, axis := range append(pa.SATAxes(), pb.SATAxes()...) {
+		projA, projB := pa.Project(axis), pb.Project(axis)
+		if projA.Max <= projB.Min || projB.Max <= projA.Min {
+			return false
+		}
+	}
+
+	return true
+
+}
@@ -7,7 +7,13 @@ import "math"
 type Space struct {
 	cells                 [][]*Cell // The cells present in the Space
 	shapes                ShapeCollection
-	cellWidth, cellHeight int // Width and Height of each Cell in "world-space" / pixels / whatever
+	cellWidth, cellHeight int        // Width and Height of each Cell in "world-space" / pixels / whatever
+	layerMatrix           [64]Layers // Which layers each layer can collide with
+
+	contactListener            ContactListener
+	contacts, prevContacts     []Contact
+	contactIDs, prevContactIDs Set[PairID]
+	checkedPairs               Set[PairID]
 }
 
 // NewSpace creates a new Space. spaceWidth and spaceHeight is the width and height of the Space (usually in pixels), which is then populated with cells of size
@@ -18,6 +24,14 @@ func NewSpace(spaceWidth, spaceHeight, cellWidth, cellHeight int) *Space {
 	sp := &Space{
 		cellWidth:  cellWidth,
 		cellHeight: cellHeight,
+
+		contactIDs:     newSet[PairID](),
+		prevContactIDs: newSet[PairID](),
+		checkedPairs:   newSet[PairID](),
+	}
+
+	for i := range sp.layerMatrix {
+		sp.layerMatrix[i] = LayerAll
 	}
 
 	sp.Resize(int(math.Ceil(float64(spaceWidth)/float64(cellWidth))), int(math.Ceil(float64(spaceHeight)/float64(cellHeight))))
@@ -102,11 +116,11 @@ func (s *Space) ForEachShape(forEach func(shape IShape, index, maxCount int) boo
 
 }
 
-// FilterShapes returns a ShapeFilter consisting of all shapes present in the Space.
+// FilterShapes returns a ShapeFilter consisting of all active shapes present in the Space.
 func (s *Space) FilterShapes() ShapeFilter {
 	return ShapeFilter{
 		operatingOn: s.shapes,
-	}
+	}.ByFunc(func(shape IShape) bool { return shape.IsActive() })
 }
 
 // Resize resizes the internal Cells array.
@@ -194,60 +208,123 @@ func (s *Space) FilterCells(bounds Bounds) CellSelection {
 
 }
 
-// func (s *Space) FilterCellsInLine(start, end Vector) CellSelection {
+// FilterCellsInLine selects the Cells that the line from start to end passes through, in order from the start of the line to the end.
+// Unlike FilterCells() with a Bounds covering the line, only the Cells the line actually crosses are selected (using Amanatides and Woo's
+// cell traversal algorithm), which is much tighter for long, diagonal lines.
+func (s *Space) FilterCellsInLine(start, end Vector) CellLineSelection {
+
+	selection := CellLineSelection{space: s}
+
+	s.walkCellsInLine(start, end, func(cx, cy int, tExit float64) bool {
+		if cell := s.Cell(cx, cy); cell != nil {
+			selection.Cells = append(selection.Cells, cell)
+		}
+		return true
+	})
+
+	return selection
+
+}
+
+// walkCellsInLine calls the given function for each cellular position inside of the Space that the line from start to end passes through, in order,
+// along with the fraction of the way along the line (from 0 to 1) at which the line leaves the cell. If the function returns false, the walk stops.
+// This is an implementation of "A Fast Voxel Traversal Algorithm for Ray Tracing" by Amanatides and Woo.
+func (s *Space) walkCellsInLine(start, end Vector, forEach func(cx, cy int, tExit float64) bool) {
+
+	cols, rows := s.WidthInCells(), s.HeightInCells()
+
+	if cols == 0 || rows == 0 {
+		return
+	}
 
-// 	cells := CellSelection{}
+	delta := end.Sub(start)
 
-// 	startX := int(math.Floor(start.X / float64(s.CellWidth)))
-// 	startY := int(math.Floor(start.Y / float64(s.CellHeight)))
+	// Cells outside of the Space don't exist, so the line is clipped to the Space first (using the Liang-Barsky algorithm);
+	// this way, the walk only ever takes as many steps as there are Cells inside of the Space, no matter how long the line is.
+	t0, t1 := 0.0, 1.0
 
-// 	endX := int(math.Floor(end.X / float64(s.CellWidth)))
-// 	endY := int(math.Floor(end.Y / float64(s.CellHeight)))
+	for _, edge := range [4][2]float64{
+		{-delta.X, start.X},
+		{delta.X, float64(s.Width()) - start.X},
+		{-delta.Y, start.Y},
+		{delta.Y, float64(s.Height()) - start.Y},
+	} {
 
-// 	cell := s.Cell(startX, startY)
-// 	endCell := s.Cell(endX, endY)
+		p, q := edge[0], edge[1]
 
-// 	if cell != nil && endCell != nil {
+		if p == 0 {
+			if q < 0 {
+				return
+			}
+			continue
+		}
 
-// 		dv := Vector{float64(endX - startX), float64(endY - startY)}.Unit()
-// 		dv.X *= float64(s.CellWidth / 2)
-// 		dv.Y *= float64(s.CellHeight / 2)
+		if r := q / p; p < 0 {
+			t0 = math.Max(t0, r)
+		} else {
+			t1 = math.Min(t1, r)
+		}
 
-// 		pX := float64(startX * s.CellWidth)
-// 		pY := float64(startY * s.CellHeight)
+		if t0 > t1 {
+			return
+		}
+
+	}
 
-// 		p := Vector{pX + float64(s.CellWidth/2), pY + float64(s.CellHeight/2)}
+	clippedStart, clippedEnd := start.Add(delta.Scale(t0)), start.Add(delta.Scale(t1))
 
-// 		alternate := false
+	cw, ch := float64(s.cellWidth), float64(s.cellHeight)
 
-// 		for cell != nil {
+	// The clipped line can end exactly on the far edges of the Space (or just past them, from floating-point error), so the Cells are clamped
+	cell := func(v Vector) (int, int) {
+		return int(clamp(math.Floor(v.X/cw), 0, float64(cols-1))), int(clamp(math.Floor(v.Y/ch), 0, float64(rows-1)))
+	}
 
-// 			if cell == endCell {
-// 				cells = append(cells, cell)
-// 				break
-// 			}
+	x, y := cell(clippedStart)
+	endX, endY := cell(clippedEnd)
+
+	// The fractions are measured along the original line, rather than the clipped one
+	stepX, tMaxX, tDeltaX := 0, math.Inf(1), math.Inf(1)
+	if delta.X > 0 {
+		stepX = 1
+		tMaxX = (float64(x+1)*cw - start.X) / delta.X
+		tDeltaX = cw / delta.X
+	} else if delta.X < 0 {
+		stepX = -1
+		tMaxX = (float64(x)*cw - start.X) / delta.X
+		tDeltaX = -cw / delta.X
+	}
 
-// 			cells = append(cells, cell)
+	stepY, tMaxY, tDeltaY := 0, math.Inf(1), math.Inf(1)
+	if delta.Y > 0 {
+		stepY = 1
+		tMaxY = (float64(y+1)*ch - start.Y) / delta.Y
+		tDeltaY = ch / delta.Y
+	} else if delta.Y < 0 {
+		stepY = -1
+		tMaxY = (float64(y)*ch - start.Y) / delta.Y
+		tDeltaY = -ch / delta.Y
+	}
 
-// 			if alternate {
-// 				p.Y += dv.Y
-// 			} else {
-// 				p.X += dv.X
-// 			}
+	// Guard against floating-point error walking past the end
+	maxSteps := abs(endX-x) + abs(endY-y) + 1
 
-// 			cx := int(math.Floor(p.X / float64(s.CellWidth)))
-// 			cy := int(math.Floor(p.Y / float64(s.CellHeight)))
+	for i := 0; i < maxSteps; i++ {
 
-// 			c := s.Cell(cx, cy)
-// 			if c != cell {
-// 				cell = c
-// 			}
-// 			alternate = !alternate
+		tExit := math.Min(math.Min(tMaxX, tMaxY), t1)
 
-// 		}
+		if !forEach(x, y, tExit) || (x == endX && y == endY) {
+			return
+		}
 
-// 	}
+		if tMaxX < tMaxY {
+			x += stepX
+			tMaxX += tDeltaX
+		} else {
+			y += stepY
+			tMaxY += tDeltaY
+		}
 
-// 	return cells
+	}
 
-// }
+}
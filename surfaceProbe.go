@@ -0,0 +1,146 @@
+package resolv
+
+import "math"
+
+// SurfaceProbeSettings is a struct of settings for ShapeBase.ProbeSurfaces().
+type SurfaceProbeSettings struct {
+	// Up is the up direction, used to determine which directions to probe in and what counts as floors, walls, and ceilings.
+	// If Up is a zero Vector, it defaults to {0, -1}, which is up in screen-space (where +Y points down).
+	Up Vector
+	// Distance is how far to probe in each direction. If Distance is 0 or less, it defaults to 1.
+	Distance float64
+	// MaxSlopeAngle is the maximum angle (in radians) between a surface's normal and the up direction for the surface to be a floor
+	// (and likewise between a surface's normal and the down direction for the surface to be a ceiling). If MaxSlopeAngle is 0, it defaults to 45 degrees.
+	MaxSlopeAngle float64
+	// TestAgainst is the set of Shapes to probe against. If TestAgainst is nil, the Shapes in the Cells near the Shape are used,
+	// filtered to the ones the Shape can collide with (see ShapeBase.CanCollideWith()). Sensors are always ignored.
+	TestAgainst ShapeIterator
+}
+
+// SurfaceInfo describes a surface found by ShapeBase.ProbeSurfaces().
+type SurfaceInfo struct {
+	Shape      IShape  // The Shape the surface belongs to. If no surface was found, this is nil.
+	Normal     Vector  // The normal of the surface.
+	SlopeAngle float64 // The angle (in radians) between the surface's normal and the normal of a perfectly flat surface in the probed direction.
+	Distance   float64 // The distance between the probing Shape and the surface along the probed direction.
+	OneWay     bool    // Whether the surface's Shape is a one-way Shape (see ShapeBase.SetOneWay()).
+	Velocity   Vector  // The velocity of the surface's Shape (see ShapeBase.Velocity()).
+}
+
+// IsFound returns whether the SurfaceInfo represents a found surface.
+func (s SurfaceInfo) IsFound() bool {
+	return s.Shape != nil
+}
+
+// IsMoving returns whether the surface's Shape is moving or rotating (as of the last Space.Step() call).
+func (s SurfaceInfo) IsMoving() bool {
+	return s.Shape != nil && (!s.Velocity.IsZero() || s.Shape.AngularVelocity() != 0)
+}
+
+// SurfaceProbe is the result of ShapeBase.ProbeSurfaces(), containing the surfaces found around a Shape.
+type SurfaceProbe struct {
+	Floor     SurfaceInfo // The floor found below the Shape.
+	Ceiling   SurfaceInfo // The ceiling found above the Shape.
+	LeftWall  SurfaceInfo // The wall found to the left of the Shape (relative to the up direction).
+	RightWall SurfaceInfo // The wall found to the right of the Shape (relative to the up direction).
+}
+
+// IsOnFloor returns whether a floor was found below the Shape.
+func (p SurfaceProbe) IsOnFloor() bool {
+	return p.Floor.IsFound()
+}
+
+// IsOnCeiling returns whether a ceiling was found above the Shape.
+func (p SurfaceProbe) IsOnCeiling() bool {
+	return p.Ceiling.IsFound()
+}
+
+// IsOnWall returns whether a wall was found on either side of the Shape.
+func (p SurfaceProbe) IsOnWall() bool {
+	return p.LeftWall.IsFound() || p.RightWall.IsFound()
+}
+
+// ProbeSurfaces casts the Shape short distances downwards, upwards, and to both sides, returning the floor, ceiling, and walls it finds.
+// This is useful for checking if a Shape is on the ground or against a wall without having to write line or shape tests by hand.
+// A surface found when probing downwards only counts as a floor if its slope is within the maximum slope angle (and similarly for ceilings);
+// surfaces found when probing to the sides only count as walls if they aren't floors or ceilings.
+// One-way Shapes are only found when probing against their direction (so a jump-through platform can be a floor, but not a ceiling).
+func (s *ShapeBase) ProbeSurfaces(settings SurfaceProbeSettings) SurfaceProbe {
+
+	up := settings.Up.Unit()
+	if up.IsZero() {
+		up = NewVector(0, -1)
+	}
+
+	distance := settings.Distance
+	if distance <= 0 {
+		distance = 1
+	}
+
+	maxSlope := settings.MaxSlopeAngle
+	if maxSlope == 0 {
+		maxSlope = ToRadians(45)
+	}
+
+	testAgainst := settings.TestAgainst
+
+	if testAgainst == nil {
+		if s.space == nil {
+			return SurfaceProbe{}
+		}
+		cellSize := min(float64(s.space.cellWidth), float64(s.space.cellHeight))
+		testAgainst = s.owner.SelectTouchingCells(1 + int(math.Ceil(distance/cellSize))).FilterShapes()
+	}
+
+	candidates := ShapeFilter{
+		operatingOn: testAgainst,
+	}.ByFunc(func(shape IShape) bool { return !shape.IsSensor() })
+
+	probe := func(dir Vector, surfaceNormal Vector) SurfaceInfo {
+
+		hit, ok := sweepShape(s.owner, dir.Scale(distance), candidates)
+
+		if !ok {
+			return SurfaceInfo{}
+		}
+
+		return SurfaceInfo{
+			Shape:      hit.Shape,
+			Normal:     hit.Normal,
+			SlopeAngle: math.Acos(clamp(hit.Normal.Dot(surfaceNormal), -1, 1)),
+			Distance:   hit.Fraction * distance,
+			OneWay:     hit.Shape.IsOneWay(),
+			Velocity:   hit.Shape.Velocity(),
+		}
+
+	}
+
+	right := up.Perp()
+	minDot := math.Cos(maxSlope)
+
+	result := SurfaceProbe{}
+
+	if floor := probe(up.Invert(), up); floor.IsFound() && floor.Normal.Dot(up) >= minDot {
+		result.Floor = floor
+	}
+
+	if ceiling := probe(up, up.Invert()); ceiling.IsFound() && ceiling.Normal.Dot(up) <= -minDot {
+		result.Ceiling = ceiling
+	}
+
+	isWall := func(normal Vector) bool {
+		d := normal.Dot(up)
+		return d < minDot && d > -minDot
+	}
+
+	if wall := probe(right.Invert(), right); wall.IsFound() && isWall(wall.Normal) {
+		result.LeftWall = wall
+	}
+
+	if wall := probe(right, right.Invert()); wall.IsFound() && isWall(wall.Normal) {
+		result.RightWall = wall
+	}
+
+	return result
+
+}
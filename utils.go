@@ -38,6 +38,90 @@ func clamp(value, min, max float64) float64 {
 	return value
 }
 
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
+
+// containsPoint returns whether the given point is inside of the Shape.
+func containsPoint(shape IShape, point Vector) bool {
+
+	switch s := shape.(type) {
+
+	case *Circle:
+		return point.DistanceSquared(s.position) <= s.radius*s.radius
+
+	case *ConvexPolygon:
+
+		count := len(s.Points)
+
+		if count < 3 || !s.Closed {
+			return false
+		}
+
+		// The point is inside if it's on the same side of every edge
+		sign := 0.0
+
+		for i := 0; i < count; i++ {
+
+			a, b := s.transformedPoint(i), s.transformedPoint((i+1)%count)
+			c := (b.X-a.X)*(point.Y-a.Y) - (b.Y-a.Y)*(point.X-a.X)
+
+			if c == 0 {
+				continue
+			}
+
+			if sign == 0 {
+				sign = c
+			} else if (c > 0) != (sign > 0) {
+				return false
+			}
+
+		}
+
+		return true
+
+	}
+
+	return false
+
+}
+
+// shapeCenter returns the center of the Shape.
+func shapeCenter(shape IShape) Vector {
+	if cp, ok := shape.(*ConvexPolygon); ok {
+		return cp.Center()
+	}
+	return shape.Position()
+}
+
+// containmentSet returns an IntersectionSet for the given Shapes if one is entirely inside of the other (which Intersection() doesn't catch,
+// as it relies on the Shapes' edges crossing), and whether it is. The set has a single Intersection at the center of the inner Shape, with no normal or MTV.
+func containmentSet(shape, other IShape) (IntersectionSet, bool) {
+
+	if !shape.Bounds().IsIntersecting(other.Bounds()) {
+		return IntersectionSet{}, false
+	}
+
+	point := shapeCenter(shape)
+
+	if !containsPoint(other, point) {
+		point = shapeCenter(other)
+		if !containsPoint(shape, point) {
+			return IntersectionSet{}, false
+		}
+	}
+
+	return IntersectionSet{
+		Intersections: []Intersection{{Point: point}},
+		Center:        point,
+		OtherShape:    other,
+	}, true
+
+}
+
 // func pow(value float64, power int) float64 {
 // 	x := value
 // 	for i := 0; i < power; i++ {
@@ -253,6 +337,7 @@ func (s shapeIDSet) idInSet(id uint32) bool {
 }
 
 var cellSelectionForEachIDSet = shapeIDSet{}
+var cellLineSelectionForEachIDSet = shapeIDSet{}
 
 /////
 
@@ -265,14 +350,21 @@ type LineTestSettings struct {
 	// set is the intersection set that contains information about the intersection, index is the index of the current index
 	// and count is the total number of intersections detected from the intersection test.
 	// The boolean the callback returns indicates whether the LineTest function should continue testing or stop at the currently found intersection.
-	OnIntersect  func(set IntersectionSet, index, max int) bool
-	callingShape IShape
+	OnIntersect func(set IntersectionSet, index, max int) bool
+	// CollisionMask is the collision layers the line tests against; Shapes not on any of these layers are skipped.
+	// If left at 0 (LayerNone), all layers are tested. This is ignored if Caster is set.
+	CollisionMask Layers
+	// Caster is the Shape casting the line, if any. If set, the Caster is skipped, as are any Shapes the Caster
+	// can't collide with (according to its collision layers, mask, group, and ignore list; see ShapeBase.CanCollideWith()).
+	Caster        IShape
+	IgnoreSensors bool // Whether to skip sensor Shapes (see ShapeBase.SetSensor()); by default, sensors are tested against.
 }
 
 var intersectionSets []IntersectionSet
 
 // LineTest instantly tests a selection of shapes against a ray / line.
 // Note that there is no MTV for these results.
+// One-way Shapes (see ShapeBase.SetOneWay()) are only hit on their solid side, and only when the line points against their direction.
 func LineTest(settings LineTestSettings) bool {
 
 	castMargin := 0.01 // Basically, the line cast starts are a smidge back so that moving to contact doesn't make future line casts fail
@@ -287,7 +379,19 @@ func LineTest(settings LineTestSettings) bool {
 
 	settings.TestAgainst.ForEach(func(other IShape) bool {
 
-		if other == settings.callingShape {
+		if settings.Caster != nil {
+			if !settings.Caster.CanCollideWith(other) {
+				return true
+			}
+		} else if settings.CollisionMask != LayerNone && !settings.CollisionMask.Has(other.CollisionLayer()) {
+			return true
+		}
+
+		if !other.IsActive() || (settings.IgnoreSensors && other.IsSensor()) {
+			return true
+		}
+
+		if other.IsOneWay() && settings.Caster != nil && settings.Caster.IsPassingThrough(other) {
 			return true
 		}
 
@@ -303,9 +407,13 @@ func LineTest(settings LineTestSettings) bool {
 
 			if len(res) > 0 {
 				for _, contactPoint := range res {
+					normal := contactPoint.Sub(shape.position).Unit()
+					if shape.IsOneWay() && !oneWayLineHit(shape, vu, normal) {
+						continue
+					}
 					contactSet.Intersections = append(contactSet.Intersections, Intersection{
 						Point:  contactPoint,
-						Normal: contactPoint.Sub(shape.position).Unit(),
+						Normal: normal,
 					})
 				}
 			}
@@ -315,6 +423,9 @@ func LineTest(settings LineTestSettings) bool {
 			for _, otherLine := range shape.Lines() {
 
 				if point, ok := line.IntersectionPointsLine(otherLine); ok {
+					if shape.IsOneWay() && !oneWayLineHit(shape, vu, otherLine.Normal()) {
+						continue
+					}
 					contactSet.Intersections = append(contactSet.Intersections, Intersection{
 						Point:  point,
 						Normal: otherLine.Normal(),
@@ -343,6 +454,11 @@ func LineTest(settings LineTestSettings) bool {
 
 			contactSet.MTV = contactSet.Intersections[0].Point.Sub(settings.Start).Sub(vu.Scale(castMargin))
 
+			if other.IsSensor() {
+				contactSet.IsSensor = true
+				contactSet.MTV = Vector{}
+			}
+
 			intersectionSets = append(intersectionSets, contactSet)
 
 		}
@@ -0,0 +1,155 @@
+package resolv
+
+import (
+	"math"
+	"sort"
+)
+
+// visibilityCircleSegments is how many segments the edge of the visible area is split into where nothing blocks it.
+const visibilityCircleSegments = 64
+
+// VisibilityPolygon returns the vertices of the area visible from the given origin out to the given radius, with sight blocked by Shapes in the Space
+// (for example, for lighting or fog of war). The vertices are ordered by their angle around the origin, going clockwise (in screen-space, where +Y points down).
+// filter is an optional function to select which Shapes block sight; if it's nil, all Shapes block sight. Sensors and inactive Shapes never block sight,
+// and neither do Shapes the origin is inside of.
+//
+// The polygon is found by sweeping around the origin, casting rays towards (and just to either side of) each corner of the ConvexPolygons, the edges of
+// the Circles as seen from the origin, and the points where Shapes cross each other or the edge of the radius. The result usually isn't convex; use
+// VisibilityFan() to split it into triangles.
+func (s *Space) VisibilityPolygon(origin Vector, radius float64, filter func(shape IShape) bool) []Vector {
+
+	if radius <= 0 {
+		return nil
+	}
+
+	area := Bounds{
+		Min: origin.Sub(Vector{radius, radius}),
+		Max: origin.Add(Vector{radius, radius}),
+	}
+
+	occluders := ShapeCollection{}
+
+	s.FilterCells(area).ForEach(func(shape IShape) bool {
+		if !shape.IsSensor() && (filter == nil || filter(shape)) && shape.Bounds().IsIntersecting(area) {
+			occluders = append(occluders, shape)
+		}
+		return true
+	})
+
+	const epsilon = 0.00001
+
+	angles := make([]float64, 0, visibilityCircleSegments+len(occluders)*12)
+
+	for i := 0; i < visibilityCircleSegments; i++ {
+		angles = append(angles, math.Pi*2*float64(i)/visibilityCircleSegments)
+	}
+
+	addPoint := func(p Vector) {
+		angle := math.Atan2(p.Y-origin.Y, p.X-origin.X)
+		angles = append(angles, angle-epsilon, angle, angle+epsilon)
+	}
+
+	rangeCircle := NewCircle(origin.X, origin.Y, radius)
+
+	for i, shape := range occluders {
+
+		switch sh := shape.(type) {
+
+		case *ConvexPolygon:
+
+			for _, p := range sh.Transformed() {
+				addPoint(p)
+			}
+
+		case *Circle:
+
+			diff := sh.position.Sub(origin)
+			dist := diff.Magnitude()
+
+			if dist <= sh.radius {
+				continue
+			}
+
+			// Cast rays at the tangents, and across the Circle between them so the shadow's edge follows its curve
+			center := math.Atan2(diff.Y, diff.X)
+			spread := math.Asin(sh.radius / dist)
+			angles = append(angles, center-spread-epsilon, center+spread+epsilon)
+			for j := 0; j <= 8; j++ {
+				angles = append(angles, center-spread+spread*2*float64(j)/8)
+			}
+
+		}
+
+		// Where the Shape crosses the edge of the visible area, or other Shapes
+		for _, inter := range rangeCircle.Intersection(shape).Intersections {
+			addPoint(inter.Point)
+		}
+
+		for _, other := range occluders[i+1:] {
+			if shape.Bounds().IsIntersecting(other.Bounds()) {
+				for _, inter := range shape.Intersection(other).Intersections {
+					addPoint(inter.Point)
+				}
+			}
+		}
+
+	}
+
+	for i := range angles {
+		angles[i] = math.Mod(angles[i]+math.Pi*2, math.Pi*2)
+	}
+
+	sort.Float64s(angles)
+
+	points := make([]Vector, 0, len(angles))
+	last := math.Inf(-1)
+
+	for _, angle := range angles {
+
+		if angle-last < epsilon/10 {
+			continue
+		}
+
+		last = angle
+
+		end := origin.Add(Vector{math.Cos(angle), math.Sin(angle)}.Scale(radius))
+		closest := end
+		closestFraction := 1.0
+
+		for _, shape := range occluders {
+			if hit, ok := rayShapeIntersection(origin, end, shape); ok && hit.Fraction < closestFraction {
+				closest = hit.Point
+				closestFraction = hit.Fraction
+			}
+		}
+
+		points = append(points, closest)
+
+	}
+
+	return points
+
+}
+
+// VisibilityFan splits a visibility polygon (as returned by Space.VisibilityPolygon()) into a fan of triangular ConvexPolygons around its origin,
+// which can be used to draw the visible area, or as sensors to check what's visible.
+func VisibilityFan(origin Vector, points []Vector) []*ConvexPolygon {
+
+	fan := make([]*ConvexPolygon, 0, len(points))
+
+	for i := range points {
+
+		a := points[i]
+		b := points[(i+1)%len(points)]
+
+		if triangleArea(origin, a, b) < 0.0001 {
+			continue
+		}
+
+		fan = append(fan, NewConvexPolygonVec(origin, []Vector{{}, a.Sub(origin), b.Sub(origin)}))
+
+	}
+
+	return fan
+
+}
@@ -0,0 +1,124 @@
+package resolv
+
+import "math"
+
+// VisionConeSettings is a struct of settings for Space.VisionCone().
+type VisionConeSettings struct {
+	Origin Vector // The point the cone is cast from (for example, an enemy's eyes).
+	// Facing is the angle (in radians) the cone faces, with 0 facing right (+X), increasing towards +Y.
+	Facing float64
+	// FieldOfView is the total width (in radians) of the cone. A FieldOfView of 2*Pi or more sees in every direction.
+	FieldOfView float64
+	Range       float64 // How far the cone reaches from the Origin.
+	// Filter is an optional function to select which Shapes can be seen (for example, only the player); if it's nil, all Shapes can be seen.
+	// Note that this includes the Shape doing the looking, if it's in the Space, so you'll usually want to filter it out.
+	Filter func(shape IShape) bool
+	// OccludedByTags and OccludedByLayers select the Shapes that block sight (for example, walls); Shapes with any of the tags or on any of the collision
+	// layers block sight. If both are empty, nothing blocks sight, and every Shape in the cone is returned.
+	OccludedByTags   Tags
+	OccludedByLayers Layers
+}
+
+// VisionCone returns the Shapes in the Space that are within the cone (or sector) described by the given settings, sorted from closest to furthest.
+// If the settings specify Shapes that block sight, Shapes that are entirely hidden behind them are filtered out; a Shape is seen if a clear line
+// can be drawn from the Origin to any of a number of points on the Shape (its closest point, center, corners, and where rays across the cone hit it)
+// that lie within the cone. Inactive Shapes are skipped, as are sensors when testing for blocked sight.
+func (s *Space) VisionCone(settings VisionConeSettings) ShapeCollection {
+
+	seen := ShapeCollection{}
+
+	if settings.Range <= 0 || settings.FieldOfView <= 0 {
+		return seen
+	}
+
+	origin := settings.Origin
+	facing := Vector{math.Cos(settings.Facing), math.Sin(settings.Facing)}
+	halfFOV := math.Min(settings.FieldOfView/2, math.Pi)
+	minDot := math.Cos(halfFOV)
+
+	occlusion := !settings.OccludedByTags.IsEmpty() || !settings.OccludedByLayers.IsEmpty()
+
+	inCone := func(point Vector) bool {
+		diff := point.Sub(origin)
+		dist := diff.Magnitude()
+		if dist > settings.Range {
+			return false
+		}
+		return dist == 0 || diff.Scale(1/dist).Dot(facing) >= minDot-1e-9
+	}
+
+	// Rays spread across the cone, about every 5 degrees, to find points on Shapes that cross the cone without having any corners inside of it
+	rayCount := int(math.Ceil(halfFOV*2/ToRadians(5))) + 1
+	rayEnds := make([]Vector, 0, rayCount)
+	for i := 0; i < rayCount; i++ {
+		angle := settings.Facing - halfFOV + halfFOV*2*float64(i)/float64(rayCount-1)
+		rayEnds = append(rayEnds, origin.Add(Vector{math.Cos(angle), math.Sin(angle)}.Scale(settings.Range)))
+	}
+
+	samples := []Vector{}
+
+	area := Bounds{
+		Min: origin.Sub(Vector{settings.Range, settings.Range}),
+		Max: origin.Add(Vector{settings.Range, settings.Range}),
+	}
+
+	s.FilterCells(area).ForEach(func(shape IShape) bool {
+
+		if settings.Filter != nil && !settings.Filter(shape) {
+			return true
+		}
+
+		center := shapeCenter(shape)
+
+		samples = samples[:0]
+		samples = append(samples, closestPointOn(shape, origin), center)
+
+		switch sh := shape.(type) {
+		case *ConvexPolygon:
+			samples = append(samples, sh.Transformed()...)
+		case *Circle:
+			// The edges of the Circle as seen from the Origin
+			side := sh.position.Sub(origin).Unit().Perp().Scale(sh.radius)
+			samples = append(samples, sh.position.Add(side), sh.position.Sub(side))
+		}
+
+		for _, end := range rayEnds {
+			if hit, ok := rayShapeIntersection(origin, end, shape); ok {
+				samples = append(samples, hit.Point)
+			}
+		}
+
+		for _, p := range samples {
+
+			if !inCone(p) {
+				continue
+			}
+
+			if !occlusion {
+				seen = append(seen, shape)
+				break
+			}
+
+			// Pull the point into the Shape slightly, so that it isn't blocked by neighboring Shapes that just touch it
+			p = p.Add(center.Sub(p).Scale(0.01))
+
+			_, blocked := s.Raycast(origin, p, func(other IShape) bool {
+				return other != shape && (other.Tags().Has(settings.OccludedByTags) || other.CollisionLayer().Has(settings.OccludedByLayers))
+			})
+
+			if !blocked {
+				seen = append(seen, shape)
+				break
+			}
+
+		}
+
+		return true
+
+	})
+
+	seen.SortByDistance(origin)
+
+	return seen
+
+}